@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+// AddressActivity is a liquidity event involving an address. Requests come
+// from the address's transactions and have a TxHash, while results of
+// batch executions come from end block events.
+type AddressActivity struct {
+	Height int64          `json:"height"`
+	Time   time.Time      `json:"time"`
+	TxHash string         `json:"tx_hash,omitempty"`
+	Type   string         `json:"type"`
+	PoolID uint64         `json:"pool_id"`
+	Event  LiquidityEvent `json:"event"`
+}
+
+// SearchAddressRequests returns the liquidity events of successful
+// transactions sent by addr in [beginHeight, endHeight]. Transactions are
+// found by message.sender, which the bank module sets to addr when it
+// escrows the offered coins.
+func SearchAddressRequests(ctx context.Context, c *Client, addr string, beginHeight, endHeight int64) ([]*AddressActivity, error) {
+	txs, err := c.SearchTxs(ctx, fmt.Sprintf(
+		`message.sender = '%s' AND message.module = '%s' AND tx.height >= %d AND tx.height <= %d`,
+		addr, liquiditytypes.ModuleName, beginHeight, endHeight))
+	if err != nil {
+		return nil, fmt.Errorf("search txs: %w", err)
+	}
+	var activities []*AddressActivity
+	for _, tx := range txs {
+		if tx.TxResult.Code != 0 {
+			continue
+		}
+		evts, err := DecodeEvents(tx.Height, tx.TxResult.Events)
+		if err != nil {
+			return nil, fmt.Errorf("decode events of tx %s: %w", tx.Hash, err)
+		}
+		if len(evts) == 0 {
+			continue
+		}
+		t, err := c.BlockTime(ctx, tx.Height)
+		if err != nil {
+			return nil, fmt.Errorf("get block time: %w", err)
+		}
+		for _, evt := range evts {
+			activities = append(activities, &AddressActivity{
+				Height: tx.Height,
+				Time:   t,
+				TxHash: tx.Hash.String(),
+				Type:   evt.GetType(),
+				PoolID: evt.GetPoolID(),
+				Event:  evt,
+			})
+		}
+	}
+	return activities, nil
+}
+
+// SortAddressActivities sorts activities by height. Within a block,
+// requests come before end block results, and the original order is kept
+// otherwise.
+func SortAddressActivities(activities []*AddressActivity) {
+	sort.SliceStable(activities, func(i, j int) bool {
+		if activities[i].Height != activities[j].Height {
+			return activities[i].Height < activities[j].Height
+		}
+		return activities[i].TxHash != "" && activities[j].TxHash == ""
+	})
+}
+
+// PoolCoinHolding is an amount of pool coin along with its share of the
+// pool and the reserve coins it can be withdrawn for, before fees.
+type PoolCoinHolding struct {
+	PoolID     uint64    `json:"pool_id"`
+	PoolCoin   sdk.Coin  `json:"pool_coin"`
+	Share      sdk.Dec   `json:"share"`
+	Underlying sdk.Coins `json:"underlying"`
+}
+
+// LoadPoolCoinHoldings resolves the pool coins among balances to their
+// underlying reserve coins at height.
+func LoadPoolCoinHoldings(ctx context.Context, c *Client, balances sdk.Coins, pools []liquiditytypes.Pool, height int64) ([]PoolCoinHolding, error) {
+	poolByDenom := make(map[string]liquiditytypes.Pool)
+	for _, pool := range pools {
+		poolByDenom[pool.PoolCoinDenom] = pool
+	}
+	var holdings []PoolCoinHolding
+	for _, coin := range balances {
+		pool, ok := poolByDenom[coin.Denom]
+		if !ok {
+			continue
+		}
+		supply, err := c.Supply(ctx, coin.Denom, WithBlockHeight(height))
+		if err != nil {
+			return nil, fmt.Errorf("get supply: %w", err)
+		}
+		h := PoolCoinHolding{PoolID: pool.Id, PoolCoin: coin, Share: sdk.ZeroDec(), Underlying: sdk.NewCoins()}
+		if supply.Amount.IsPositive() {
+			h.Share = coin.Amount.ToDec().Quo(supply.Amount.ToDec())
+			for _, denom := range pool.ReserveCoinDenoms {
+				reserve, err := c.Balance(ctx, pool.ReserveAccountAddress, denom, WithBlockHeight(height))
+				if err != nil {
+					return nil, fmt.Errorf("get balance: %w", err)
+				}
+				h.Underlying = h.Underlying.Add(sdk.NewCoin(denom, reserve.Amount.Mul(coin.Amount).Quo(supply.Amount)))
+			}
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, nil
+}
+
+type AddressReport struct {
+	SummaryMetadata
+	Address    string             `json:"address"`
+	Balances   sdk.Coins          `json:"balances"`
+	PoolCoins  []PoolCoinHolding  `json:"pool_coins"`
+	Activities []*AddressActivity `json:"activities"`
+}
+
+// WriteAddressReport writes report to w in the given format.
+func WriteAddressReport(w io.Writer, format string, report AddressReport) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, a := range report.Activities {
+			if err := enc.Encode(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTable:
+		return writeAddressTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeAddressTable(w io.Writer, report AddressReport) error {
+	fmt.Fprintf(w, "Address %s\n", report.Address)
+	fmt.Fprintf(w, "* balances at block %d: %s\n", report.EndHeight, report.Balances)
+	for _, h := range report.PoolCoins {
+		fmt.Fprintf(w, "* pool %d: %s (%s%% of the pool) = %s\n",
+			h.PoolID, h.PoolCoin, formatPercent(h.Share), h.Underlying)
+	}
+	fmt.Fprintf(w, "* %d activities from block %d to %d\n", len(report.Activities), report.BeginHeight, report.EndHeight)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "HEIGHT\tTIME\tTYPE\tPOOL\tDETAILS")
+	for _, a := range report.Activities {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n",
+			a.Height, a.Time.Format(time.RFC3339), a.Type, a.PoolID, DescribeEvent(a.Event))
+	}
+	return tw.Flush()
+}
+
+// DescribeEvent returns a short human readable description of evt.
+func DescribeEvent(evt LiquidityEvent) string {
+	switch evt := evt.(type) {
+	case *CreatePoolEvent:
+		return fmt.Sprintf("create %s with %s", evt.PoolName, evt.DepositCoins)
+	case *DepositWithinBatchEvent:
+		return fmt.Sprintf("request deposit of %s", evt.DepositCoins)
+	case *WithdrawWithinBatchEvent:
+		return fmt.Sprintf("request withdrawal of %s", evt.PoolCoin)
+	case *SwapWithinBatchEvent:
+		return fmt.Sprintf("request swap of %s for %s at %s", evt.OfferCoin, evt.DemandCoinDenom, evt.OrderPrice)
+	case *SwapTransactedEvent:
+		if !evt.Success {
+			return fmt.Sprintf("swap of %s failed", evt.ExchangedOfferCoin.Denom)
+		}
+		return fmt.Sprintf("swapped %s for %s at %s", evt.ExchangedOfferCoin, evt.ExchangedDemandCoin, evt.SwapPrice)
+	case *DepositToPoolEvent:
+		if !evt.Success {
+			return fmt.Sprintf("deposit failed, refunded %s", evt.RefundedCoins)
+		}
+		return fmt.Sprintf("deposited %s for %s", evt.AcceptedCoins, evt.PoolCoin)
+	case *WithdrawFromPoolEvent:
+		if !evt.Success {
+			return fmt.Sprintf("withdrawal of %s failed", evt.PoolCoin)
+		}
+		return fmt.Sprintf("withdrew %s for %s", evt.PoolCoin, evt.WithdrawCoins)
+	default:
+		var attrs []string
+		for k, v := range evt.GetAttributes() {
+			attrs = append(attrs, k+"="+v)
+		}
+		sort.Strings(attrs)
+		return strings.Join(attrs, " ")
+	}
+}
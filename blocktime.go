@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const blockTimeIndexHeader = "# chain-id: "
+
+// BlockTimeIndex is an append-only, on-disk index of block heights and
+// their times. The first line of the file holds the chain ID of the blocks,
+// and each following line holds a height and an RFC3339 time separated by
+// a comma.
+type BlockTimeIndex struct {
+	mu      sync.RWMutex
+	file    *os.File
+	times   map[int64]time.Time
+	heights []int64 // sorted
+}
+
+// OpenBlockTimeIndex loads the index of chainID stored at path, creating the
+// file if it does not exist. An incomplete last line is truncated.
+func OpenBlockTimeIndex(path string, chainID string) (*BlockTimeIndex, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	idx := &BlockTimeIndex{
+		file:  f,
+		times: make(map[int64]time.Time),
+	}
+	if err := idx.load(chainID); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *BlockTimeIndex) load(chainID string) error {
+	r := bufio.NewReader(idx.file)
+	var offset int64
+	for lineNum := 1; ; lineNum++ {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			// An incomplete last line is left by an interrupted append, so
+			// drop it instead of failing.
+			if line != "" {
+				if err := idx.file.Truncate(offset); err != nil {
+					return fmt.Errorf("truncate incomplete line %d: %w", lineNum, err)
+				}
+			}
+			if offset == 0 {
+				if _, err := fmt.Fprintf(idx.file, "%s%s\n", blockTimeIndexHeader, chainID); err != nil {
+					return fmt.Errorf("write header: %w", err)
+				}
+			}
+			break
+		} else if err != nil {
+			return fmt.Errorf("read index: %w", err)
+		}
+		offset += int64(len(line))
+		line = strings.TrimSpace(line)
+		if lineNum == 1 {
+			if !strings.HasPrefix(line, blockTimeIndexHeader) {
+				return fmt.Errorf("index has no chain id; remove it to rebuild")
+			}
+			if id := strings.TrimPrefix(line, blockTimeIndexHeader); id != chainID {
+				return fmt.Errorf("index belongs to chain %q, not %q", id, chainID)
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		h, t, err := parseBlockTimeLine(line)
+		if err != nil {
+			return fmt.Errorf("parse line %d: %w", lineNum, err)
+		}
+		if _, ok := idx.times[h]; !ok {
+			idx.heights = append(idx.heights, h)
+		}
+		idx.times[h] = t
+	}
+	sort.Slice(idx.heights, func(i, j int) bool { return idx.heights[i] < idx.heights[j] })
+	return nil
+}
+
+func parseBlockTimeLine(line string) (int64, time.Time, error) {
+	chunks := strings.SplitN(line, ",", 2)
+	if len(chunks) != 2 {
+		return 0, time.Time{}, fmt.Errorf("malformed line: %q", line)
+	}
+	h, err := strconv.ParseInt(chunks[0], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("parse height: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339Nano, chunks[1])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("parse time: %w", err)
+	}
+	return h, t, nil
+}
+
+func (idx *BlockTimeIndex) Close() error {
+	return idx.file.Close()
+}
+
+// Get returns the indexed time of the block at height h.
+func (idx *BlockTimeIndex) Get(h int64) (time.Time, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	t, ok := idx.times[h]
+	return t, ok
+}
+
+// Put records the time of the block at height h.
+func (idx *BlockTimeIndex) Put(h int64, t time.Time) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.times[h]; ok {
+		return nil
+	}
+	if _, err := fmt.Fprintf(idx.file, "%d,%s\n", h, t.UTC().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+	idx.times[h] = t
+	i := sort.Search(len(idx.heights), func(i int) bool { return idx.heights[i] > h })
+	idx.heights = append(idx.heights, 0)
+	copy(idx.heights[i+1:], idx.heights[i:])
+	idx.heights[i] = h
+	return nil
+}
+
+// Bracket narrows the range [lo, hi] using indexed heights, so that the time
+// of lo is not after t and the time of hi is after t. The times of lo and hi
+// must be given as tlo and thi. Block times increase with heights, so the
+// indexed heights inside the range are binary-searched.
+func (idx *BlockTimeIndex) Bracket(t time.Time, lo, hi int64, tlo, thi time.Time) (int64, int64, time.Time, time.Time) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	begin := sort.Search(len(idx.heights), func(i int) bool { return idx.heights[i] > lo })
+	end := sort.Search(len(idx.heights), func(i int) bool { return idx.heights[i] >= hi })
+	if begin >= end {
+		return lo, hi, tlo, thi
+	}
+	heights := idx.heights[begin:end]
+	i := sort.Search(len(heights), func(i int) bool { return idx.times[heights[i]].After(t) })
+	if i > 0 {
+		lo = heights[i-1]
+		tlo = idx.times[lo]
+	}
+	if i < len(heights) {
+		hi = heights[i]
+		thi = idx.times[hi]
+	}
+	return lo, hi, tlo, thi
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+// Candle is the OHLCV candle of a pool over an interval. Prices are swap
+// prices of executed batches, in X per Y. VolumeX and VolumeY are the
+// amounts of X and Y bought or sold by the interval's swaps.
+type Candle struct {
+	Time     time.Time `json:"time"`
+	PoolID   uint64    `json:"pool_id"`
+	XDenom   string    `json:"x_denom"`
+	YDenom   string    `json:"y_denom"`
+	Open     sdk.Dec   `json:"open"`
+	High     sdk.Dec   `json:"high"`
+	Low      sdk.Dec   `json:"low"`
+	Close    sdk.Dec   `json:"close"`
+	VolumeX  sdk.Int   `json:"volume_x"`
+	VolumeY  sdk.Int   `json:"volume_y"`
+	NumSwaps int       `json:"num_swaps"`
+}
+
+type candleKey struct {
+	poolID uint64
+	time   time.Time
+}
+
+// Candles builds candles from successful swaps, aligned like TimeSeries.
+type Candles struct {
+	interval time.Duration
+	pools    map[uint64]liquiditytypes.Pool
+	candles  map[candleKey]*Candle
+}
+
+func NewCandles(pools []liquiditytypes.Pool, interval time.Duration) *Candles {
+	cs := &Candles{
+		interval: interval,
+		pools:    make(map[uint64]liquiditytypes.Pool),
+		candles:  make(map[candleKey]*Candle),
+	}
+	for _, pool := range pools {
+		cs.pools[pool.Id] = pool
+	}
+	return cs
+}
+
+// Add adds evt, emitted in a block at blockTime, to its candle. Events
+// must be added in the order they were emitted. Events other than
+// successful swaps are ignored.
+func (cs *Candles) Add(evt LiquidityEvent, blockTime time.Time) error {
+	ste, ok := evt.(*SwapTransactedEvent)
+	if !ok || !ste.Success {
+		return nil
+	}
+	pool, ok := cs.pools[ste.PoolID]
+	if !ok {
+		return fmt.Errorf("pool id not found: %d", ste.PoolID)
+	}
+	key := candleKey{ste.PoolID, blockTime.UTC().Truncate(cs.interval)}
+	c, ok := cs.candles[key]
+	if !ok {
+		c = &Candle{
+			Time:    key.time,
+			PoolID:  key.poolID,
+			XDenom:  pool.ReserveCoinDenoms[0],
+			YDenom:  pool.ReserveCoinDenoms[1],
+			Open:    ste.SwapPrice,
+			High:    ste.SwapPrice,
+			Low:     ste.SwapPrice,
+			VolumeX: sdk.ZeroInt(),
+			VolumeY: sdk.ZeroInt(),
+		}
+		cs.candles[key] = c
+	}
+	if ste.SwapPrice.GT(c.High) {
+		c.High = ste.SwapPrice
+	}
+	if ste.SwapPrice.LT(c.Low) {
+		c.Low = ste.SwapPrice
+	}
+	c.Close = ste.SwapPrice
+	if ste.ExchangedOfferCoin.Denom == c.XDenom {
+		c.VolumeX = c.VolumeX.Add(ste.ExchangedOfferCoin.Amount)
+		c.VolumeY = c.VolumeY.Add(ste.ExchangedDemandCoin.Amount)
+	} else {
+		c.VolumeX = c.VolumeX.Add(ste.ExchangedDemandCoin.Amount)
+		c.VolumeY = c.VolumeY.Add(ste.ExchangedOfferCoin.Amount)
+	}
+	c.NumSwaps++
+	return nil
+}
+
+// Candles returns the candles ordered by pool id, then by time. Intervals
+// without swaps have no candle.
+func (cs *Candles) Candles() []*Candle {
+	candles := make([]*Candle, 0, len(cs.candles))
+	for _, c := range cs.candles {
+		candles = append(candles, c)
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		if candles[i].PoolID != candles[j].PoolID {
+			return candles[i].PoolID < candles[j].PoolID
+		}
+		return candles[i].Time.Before(candles[j].Time)
+	})
+	return candles
+}
+
+type CandleReport struct {
+	SummaryMetadata
+	Interval string    `json:"interval"`
+	Candles  []*Candle `json:"candles"`
+}
+
+// WriteCandleReport writes report to w in the given format.
+func WriteCandleReport(w io.Writer, format string, report CandleReport) error {
+	switch format {
+	case FormatCSV:
+		return writeCandleCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, c := range report.Candles {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeCandleCSV(w io.Writer, report CandleReport) error {
+	records := [][]string{{
+		"time", "id", "x_denom", "y_denom",
+		"open", "high", "low", "close", "volume_x", "volume_y", "swaps",
+	}}
+	for _, c := range report.Candles {
+		records = append(records, []string{
+			c.Time.Format(time.RFC3339),
+			strconv.FormatUint(c.PoolID, 10),
+			c.XDenom,
+			c.YDenom,
+			c.Open.String(),
+			c.High.String(),
+			c.Low.String(),
+			c.Close.String(),
+			c.VolumeX.String(),
+			c.VolumeY.String(),
+			strconv.Itoa(c.NumSwaps),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
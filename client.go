@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -11,20 +12,25 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/kv"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/gorilla/websocket"
 	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
 	abcitypes "github.com/tendermint/tendermint/abci/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	rpc "github.com/tendermint/tendermint/rpc/client/http"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 )
 
 type Client struct {
-	cfg       ClientConfig
-	grpcConn  *grpc.ClientConn
-	rpcClient rpcclient.Client
+	cfg        ClientConfig
+	grpcConn   *grpc.ClientConn
+	rpcClient  rpcclient.Client
+	blockTimes *BlockTimeIndex
 }
 
 func NewClient(cfg ClientConfig) (*Client, error) {
@@ -45,24 +51,47 @@ func NewClient(cfg ClientConfig) (*Client, error) {
 		Jar:           nil,
 		Timeout:       0,
 	}
+	var rt http.RoundTripper = http.DefaultTransport
 	if cfg.RPC.Token != "" {
-		httpClient.Transport = AddTokenRoundTripper{
-			rt:    http.DefaultTransport,
+		rt = AddTokenRoundTripper{
+			rt:    rt,
 			token: cfg.RPC.Token,
 		}
 	}
+	httpClient.Transport = StatusCheckRoundTripper{rt: rt}
 	rpcClient, err := rpc.NewWithClient(cfg.RPC.URL, "/websocket", httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("new rpc client: %w", err)
 	}
-	return &Client{
+	c := &Client{
 		cfg:       cfg,
 		grpcConn:  grpcConn,
 		rpcClient: rpcClient,
-	}, nil
+	}
+	if cfg.BlockTimeIndex != "" {
+		// The index is tied to the chain of the node, so that pointing the
+		// config at another network doesn't reuse block times of the old one.
+		st, err := c.status(context.Background())
+		if err != nil {
+			grpcConn.Close()
+			return nil, fmt.Errorf("get chain id: %w", err)
+		}
+		c.blockTimes, err = OpenBlockTimeIndex(cfg.BlockTimeIndex, st.NodeInfo.Network)
+		if err != nil {
+			grpcConn.Close()
+			return nil, fmt.Errorf("open block time index: %w", err)
+		}
+	}
+	return c, nil
 }
 
 func (c *Client) Close() error {
+	if c.blockTimes != nil {
+		if err := c.blockTimes.Close(); err != nil {
+			c.grpcConn.Close()
+			return fmt.Errorf("close block time index: %w", err)
+		}
+	}
 	return c.grpcConn.Close()
 }
 
@@ -73,14 +102,38 @@ func (c *Client) withToken(ctx context.Context) context.Context {
 	return metadata.AppendToOutgoingContext(ctx, "Authorization", c.cfg.GRPC.Token)
 }
 
+func (c *Client) status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	var resp *coretypes.ResultStatus
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.rpcClient.Status(ctx)
+		return
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (c *Client) LatestBlockHeight(ctx context.Context) (int64, error) {
-	resp, err := c.rpcClient.Status(ctx)
+	resp, err := c.status(ctx)
 	if err != nil {
 		return 0, err
 	}
 	return resp.SyncInfo.LatestBlockHeight, nil
 }
 
+// EarliestBlockHeight returns the lowest block height available on the node,
+// unless overridden by RPCConfig.EarliestBlockHeight.
+func (c *Client) EarliestBlockHeight(ctx context.Context) (int64, error) {
+	if c.cfg.RPC.EarliestBlockHeight > 0 {
+		return c.cfg.RPC.EarliestBlockHeight, nil
+	}
+	resp, err := c.status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return resp.SyncInfo.EarliestBlockHeight, nil
+}
+
 func (c *Client) Pools(ctx context.Context, options ...ClientOption) ([]liquiditytypes.Pool, error) {
 	opts := ClientOptions{}
 	for _, opt := range options {
@@ -93,8 +146,11 @@ func (c *Client) Pools(ctx context.Context, options ...ClientOption) ([]liquidit
 	lqc := liquiditytypes.NewQueryClient(c.grpcConn)
 
 	var md metadata.MD
-	resp, err := lqc.LiquidityPools(c.withToken(ctx), &liquiditytypes.QueryLiquidityPoolsRequest{}, grpc.Header(&md))
-	if err != nil {
+	var resp *liquiditytypes.QueryLiquidityPoolsResponse
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = lqc.LiquidityPools(c.withToken(ctx), &liquiditytypes.QueryLiquidityPoolsRequest{}, grpc.Header(&md))
+		return
+	}); err != nil {
 		return nil, err
 	}
 
@@ -119,8 +175,11 @@ func (c *Client) AllBalances(ctx context.Context, addr string, options ...Client
 	bqc := banktypes.NewQueryClient(c.grpcConn)
 
 	var md metadata.MD
-	resp, err := bqc.AllBalances(c.withToken(ctx), &banktypes.QueryAllBalancesRequest{Address: addr}, grpc.Header(&md))
-	if err != nil {
+	var resp *banktypes.QueryAllBalancesResponse
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = bqc.AllBalances(c.withToken(ctx), &banktypes.QueryAllBalancesRequest{Address: addr}, grpc.Header(&md))
+		return
+	}); err != nil {
 		return nil, err
 	}
 
@@ -145,8 +204,11 @@ func (c *Client) Balance(ctx context.Context, addr, denom string, options ...Cli
 	bqc := banktypes.NewQueryClient(c.grpcConn)
 
 	var md metadata.MD
-	resp, err := bqc.Balance(c.withToken(ctx), &banktypes.QueryBalanceRequest{Address: addr, Denom: denom}, grpc.Header(&md))
-	if err != nil {
+	var resp *banktypes.QueryBalanceResponse
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = bqc.Balance(c.withToken(ctx), &banktypes.QueryBalanceRequest{Address: addr, Denom: denom}, grpc.Header(&md))
+		return
+	}); err != nil {
 		return sdk.Coin{}, err
 	}
 
@@ -159,31 +221,191 @@ func (c *Client) Balance(ctx context.Context, addr, denom string, options ...Cli
 	return *resp.Balance, nil
 }
 
+func (c *Client) Supply(ctx context.Context, denom string, options ...ClientOption) (sdk.Coin, error) {
+	opts := ClientOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.blockHeight != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(*opts.blockHeight, 10))
+	}
+
+	bqc := banktypes.NewQueryClient(c.grpcConn)
+
+	var md metadata.MD
+	var resp *banktypes.QuerySupplyOfResponse
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = bqc.SupplyOf(c.withToken(ctx), &banktypes.QuerySupplyOfRequest{Denom: denom}, grpc.Header(&md))
+		return
+	}); err != nil {
+		return sdk.Coin{}, err
+	}
+
+	if opts.blockHeight != nil {
+		if err := CheckBlockHeight(md, *opts.blockHeight); err != nil {
+			return sdk.Coin{}, fmt.Errorf("check block height: %w", err)
+		}
+	}
+
+	return resp.Amount, nil
+}
+
+// IterateAllBalances calls fn for every balance of every account. The
+// SDK's gRPC queries can't walk accounts or the holders of a denom, so the
+// balance store of the bank module is read through ABCI subspace queries,
+// one for each first byte of the address, keeping each response small and
+// each retry cheap.
+func (c *Client) IterateAllBalances(ctx context.Context, fn func(addr sdk.AccAddress, coin sdk.Coin) error, options ...ClientOption) error {
+	opts := ClientOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	var queryOpts rpcclient.ABCIQueryOptions
+	if opts.blockHeight != nil {
+		queryOpts.Height = *opts.blockHeight
+	}
+
+	for i := 0; i < 256; i++ {
+		prefix := append(append([]byte{}, banktypes.BalancesPrefix...), byte(i))
+		var resp *coretypes.ResultABCIQuery
+		if err := c.retry(ctx, func(ctx context.Context) (err error) {
+			resp, err = c.rpcClient.ABCIQueryWithOptions(ctx, "/store/"+banktypes.StoreKey+"/subspace", prefix, queryOpts)
+			return
+		}); err != nil {
+			return err
+		}
+		if !resp.Response.IsOK() {
+			return fmt.Errorf("query balances: %s", resp.Response.Log)
+		}
+		if opts.blockHeight != nil && resp.Response.Height != *opts.blockHeight {
+			return fmt.Errorf("mismatching block height; got %d, expected %d", resp.Response.Height, *opts.blockHeight)
+		}
+
+		var pairs kv.Pairs
+		if err := pairs.Unmarshal(resp.Response.Value); err != nil {
+			return fmt.Errorf("unmarshal balances: %w", err)
+		}
+		for _, pair := range pairs.Pairs {
+			key := pair.Key[len(banktypes.BalancesPrefix):]
+			if len(key) < sdk.AddrLen {
+				return fmt.Errorf("invalid balance key: %X", pair.Key)
+			}
+			var coin sdk.Coin
+			if err := coin.Unmarshal(pair.Value); err != nil {
+				return fmt.Errorf("unmarshal balance: %w", err)
+			}
+			if err := fn(banktypes.AddressFromBalancesStore(key), coin); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BlockTime returns the time of the block at height, answering from the
+// block time index when possible.
 func (c *Client) BlockTime(ctx context.Context, height int64) (time.Time, error) {
-	resp, err := c.rpcClient.Block(ctx, &height)
-	if err != nil {
+	if c.blockTimes != nil {
+		if t, ok := c.blockTimes.Get(height); ok {
+			return t, nil
+		}
+	}
+	var resp *coretypes.ResultBlock
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.rpcClient.Block(ctx, &height)
+		return
+	}); err != nil {
 		return time.Time{}, err
 	}
+	if c.blockTimes != nil {
+		if err := c.blockTimes.Put(resp.Block.Height, resp.Block.Time); err != nil {
+			return time.Time{}, fmt.Errorf("put block time index: %w", err)
+		}
+	}
 	return resp.Block.Time, nil
 }
 
+// SearchBlockHeightByTime returns the lowest block height whose time is after t.
+// If t is after the latest block, the latest block height is returned.
+// If t is before the earliest block available on the node, a
+// *BeforeHistoryError is returned.
+//
+// Heights are guessed by interpolating between block times, starting from
+// the tightest range known to the block time index, and falling back to
+// bisection whenever a guess fails to halve the range.
 func (c *Client) SearchBlockHeightByTime(ctx context.Context, t time.Time) (int64, error) {
+	beginHeight, err := c.EarliestBlockHeight(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get earliest block height: %w", err)
+	}
 	endHeight, err := c.LatestBlockHeight(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("get latest block height: %w", err)
 	}
 
-	h := sort.Search(int(endHeight), func(h int) bool {
-		if h < 5200791 { // TODO: remove this hard coded minimum height
-			return false
+	beginTime, err := c.BlockTime(ctx, beginHeight)
+	if err != nil {
+		return 0, fmt.Errorf("get block time at height %d: %w", beginHeight, err)
+	}
+	if t.Before(beginTime) {
+		return 0, &BeforeHistoryError{Time: t, EarliestBlockHeight: beginHeight, EarliestBlockTime: beginTime}
+	}
+	endTime, err := c.BlockTime(ctx, endHeight)
+	if err != nil {
+		return 0, fmt.Errorf("get block time at height %d: %w", endHeight, err)
+	}
+	if !endTime.After(t) {
+		return endHeight, nil
+	}
+
+	// Invariant: time of lo is not after t, time of hi is after t.
+	lo, hi, tlo, thi := beginHeight, endHeight, beginTime, endTime
+	if c.blockTimes != nil {
+		lo, hi, tlo, thi = c.blockTimes.Bracket(t, lo, hi, tlo, thi)
+	}
+	bisect := false
+	for hi-lo > 1 {
+		var h int64
+		if bisect {
+			h = lo + (hi-lo)/2
+		} else {
+			h = lo + int64(float64(hi-lo)*float64(t.Sub(tlo))/float64(thi.Sub(tlo)))
+			if h <= lo {
+				h = lo + 1
+			} else if h >= hi {
+				h = hi - 1
+			}
 		}
-		t2, err := c.BlockTime(ctx, int64(h))
+		width := hi - lo
+		ht, err := c.BlockTime(ctx, h)
 		if err != nil {
-			panic(err)
+			return 0, fmt.Errorf("get block time at height %d: %w", h, err)
 		}
-		return t2.After(t)
-	})
-	return int64(h), nil
+		if ht.After(t) {
+			hi, thi = h, ht
+		} else {
+			lo, tlo = h, ht
+		}
+		bisect = !bisect && hi-lo > width/2
+	}
+	return hi, nil
+}
+
+// SearchLastBlockHeightByTime returns the highest block height whose time
+// is not after t.
+func (c *Client) SearchLastBlockHeightByTime(ctx context.Context, t time.Time) (int64, error) {
+	h, err := c.SearchBlockHeightByTime(ctx, t)
+	if err != nil {
+		return 0, err
+	}
+	ht, err := c.BlockTime(ctx, h)
+	if err != nil {
+		return 0, fmt.Errorf("get block time at height %d: %w", h, err)
+	}
+	if ht.After(t) {
+		h--
+	}
+	return h, nil
 }
 
 func (c *Client) SearchBlockHeights(ctx context.Context, query string) ([]int64, error) {
@@ -191,8 +413,11 @@ func (c *Client) SearchBlockHeights(ctx context.Context, query string) ([]int64,
 	maxPage := -1
 	var heights []int64
 	for page := 1; maxPage == -1 || page <= maxPage; page++ {
-		resp, err := c.rpcClient.BlockSearch(ctx, query, &page, &pageSize, "asc")
-		if err != nil {
+		var resp *coretypes.ResultBlockSearch
+		if err := c.retry(ctx, func(ctx context.Context) (err error) {
+			resp, err = c.rpcClient.BlockSearch(ctx, query, &page, &pageSize, "asc")
+			return
+		}); err != nil {
 			return nil, err
 		}
 		if resp.TotalCount == 0 {
@@ -208,14 +433,238 @@ func (c *Client) SearchBlockHeights(ctx context.Context, query string) ([]int64,
 	return heights, nil
 }
 
+// SearchEventBlockHeights returns the sorted, deduplicated heights of blocks
+// in [beginHeight, endHeight] that contain any of the given liquidity events.
+func (c *Client) SearchEventBlockHeights(ctx context.Context, eventTypes []string, beginHeight, endHeight int64) ([]int64, error) {
+	queries := make(map[string]string)
+	for _, eventType := range eventTypes {
+		queries[eventType] = fmt.Sprintf(`%s.pool_id EXISTS AND block.height >= %d AND block.height <= %d`, eventType, beginHeight, endHeight)
+	}
+	return c.searchBlockHeightsAny(ctx, queries)
+}
+
+// SearchAddressBlockHeights returns the sorted, deduplicated heights of
+// blocks in [beginHeight, endHeight] whose end block events involve addr.
+func (c *Client) SearchAddressBlockHeights(ctx context.Context, addr string, beginHeight, endHeight int64) ([]int64, error) {
+	queries := make(map[string]string)
+	for eventType, key := range map[string]string{
+		liquiditytypes.EventTypeSwapTransacted:   liquiditytypes.AttributeValueSwapRequester,
+		liquiditytypes.EventTypeDepositToPool:    liquiditytypes.AttributeValueDepositor,
+		liquiditytypes.EventTypeWithdrawFromPool: liquiditytypes.AttributeValueWithdrawer,
+	} {
+		queries[eventType] = fmt.Sprintf(`%s.%s = '%s' AND block.height >= %d AND block.height <= %d`, eventType, key, addr, beginHeight, endHeight)
+	}
+	return c.searchBlockHeightsAny(ctx, queries)
+}
+
+// searchBlockHeightsAny returns the sorted, deduplicated heights of blocks
+// matching any of queries, which maps names used in errors to queries.
+func (c *Client) searchBlockHeightsAny(ctx context.Context, queries map[string]string) ([]int64, error) {
+	heightSet := make(map[int64]struct{})
+	for name, query := range queries {
+		heights, err := c.SearchBlockHeights(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("search %s: %w", name, err)
+		}
+		for _, h := range heights {
+			heightSet[h] = struct{}{}
+		}
+	}
+	heights := make([]int64, 0, len(heightSet))
+	for h := range heightSet {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+// SearchTxs returns all transactions matching query in ascending order.
+func (c *Client) SearchTxs(ctx context.Context, query string) ([]*coretypes.ResultTx, error) {
+	pageSize := 100
+	maxPage := -1
+	var txs []*coretypes.ResultTx
+	for page := 1; maxPage == -1 || page <= maxPage; page++ {
+		var resp *coretypes.ResultTxSearch
+		if err := c.retry(ctx, func(ctx context.Context) (err error) {
+			resp, err = c.rpcClient.TxSearch(ctx, query, false, &page, &pageSize, "asc")
+			return
+		}); err != nil {
+			return nil, err
+		}
+		if resp.TotalCount == 0 {
+			break
+		}
+		txs = append(txs, resp.Txs...)
+		if maxPage == -1 {
+			maxPage = int(math.Ceil(float64(resp.TotalCount) / float64(pageSize)))
+		}
+	}
+	return txs, nil
+}
+
 func (c *Client) EndBlockEvents(ctx context.Context, blockHeight int64) ([]abcitypes.Event, error) {
-	resp, err := c.rpcClient.BlockResults(ctx, &blockHeight)
-	if err != nil {
+	var resp *coretypes.ResultBlockResults
+	if err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.rpcClient.BlockResults(ctx, &blockHeight)
+		return
+	}); err != nil {
 		return nil, err
 	}
 	return resp.EndBlockEvents, nil
 }
 
+// SubscribeNewBlockHeights subscribes to new blocks over a new websocket
+// connection and sends their heights to the returned channel. The channel is
+// closed and the connection stopped when ctx is done. A new connection is
+// made on each call, since the websocket of the configured RPC client can't
+// be restarted once stopped.
+//
+// Tendermint's websocket client sends no headers, so the RPC token is not
+// sent. An error wrapping websocket.ErrBadHandshake is returned if the node
+// rejects the connection, such as when it requires the token.
+func (c *Client) SubscribeNewBlockHeights(ctx context.Context) (<-chan int64, error) {
+	ws, err := rpc.New(c.cfg.RPC.URL, "/websocket")
+	if err != nil {
+		return nil, fmt.Errorf("new rpc client: %w", err)
+	}
+	if err := ws.Start(); err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && c.cfg.RPC.Token != "" {
+			return nil, fmt.Errorf("start websocket: %w; the RPC token can't be sent over the websocket", err)
+		}
+		return nil, fmt.Errorf("start websocket: %w", err)
+	}
+	events, err := ws.Subscribe(ctx, "gravity-dex-stats", tmtypes.EventQueryNewBlock.String())
+	if err != nil {
+		_ = ws.Stop()
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+	heights := make(chan int64)
+	go func() {
+		defer close(heights)
+		defer func() { _ = ws.Stop() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+				data, ok := evt.Data.(tmtypes.EventDataNewBlock)
+				if !ok {
+					continue
+				}
+				select {
+				case heights <- data.Block.Height:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return heights, nil
+}
+
+// BlockEvents holds the end block events of a block.
+type BlockEvents struct {
+	Height int64
+	Time   time.Time
+	Events []abcitypes.Event
+}
+
+// IterateEndBlockEvents fetches end block events for heights using up to
+// concurrency parallel requests, and calls fn for each height in the order
+// of heights. The first error returned by a request or fn cancels the rest.
+func (c *Client) IterateEndBlockEvents(ctx context.Context, heights []int64, concurrency int, fn func(height int64, events []abcitypes.Event) error) error {
+	return c.iterateBlockEvents(ctx, heights, concurrency, false, func(block BlockEvents) error {
+		return fn(block.Height, block.Events)
+	})
+}
+
+// IterateBlockEvents is like IterateEndBlockEvents, but also fetches the
+// time of each block.
+func (c *Client) IterateBlockEvents(ctx context.Context, heights []int64, concurrency int, fn func(block BlockEvents) error) error {
+	return c.iterateBlockEvents(ctx, heights, concurrency, true, fn)
+}
+
+func (c *Client) iterateBlockEvents(ctx context.Context, heights []int64, concurrency int, withTime bool, fn func(block BlockEvents) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		block BlockEvents
+		err   error
+	}
+
+	// futures bounds the number of in-flight requests while preserving order.
+	// One request is always being waited on by the loop below, hence the -1.
+	futures := make(chan chan result, concurrency-1)
+	go func() {
+		defer close(futures)
+		for _, height := range heights {
+			ch := make(chan result, 1)
+			select {
+			case futures <- ch:
+			case <-ctx.Done():
+				return
+			}
+			go func(height int64) {
+				block := BlockEvents{Height: height}
+				var err error
+				block.Events, err = c.EndBlockEvents(ctx, height)
+				if err != nil {
+					ch <- result{err: fmt.Errorf("get end block events at height %d: %w", height, err)}
+					return
+				}
+				if withTime {
+					block.Time, err = c.BlockTime(ctx, height)
+					if err != nil {
+						ch <- result{err: fmt.Errorf("get block time at height %d: %w", height, err)}
+						return
+					}
+				}
+				ch <- result{block: block}
+			}(height)
+		}
+	}()
+
+	for ch := range futures {
+		r := <-ch
+		if r.err != nil {
+			return r.err
+		}
+		if err := fn(r.block); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// BeforeHistoryError is returned when a requested time or height is before
+// the earliest block available on the node. Time is zero if a height was
+// requested.
+type BeforeHistoryError struct {
+	Time                time.Time
+	Height              int64
+	EarliestBlockHeight int64
+	EarliestBlockTime   time.Time
+}
+
+func (err *BeforeHistoryError) Error() string {
+	if err.Time.IsZero() {
+		return fmt.Sprintf(
+			"height %d is before the node's history; earliest block is %d at %s",
+			err.Height, err.EarliestBlockHeight, err.EarliestBlockTime.Format(time.RFC3339))
+	}
+	return fmt.Sprintf(
+		"time %s is before the node's history; earliest block is %d at %s",
+		err.Time.Format(time.RFC3339), err.EarliestBlockHeight, err.EarliestBlockTime.Format(time.RFC3339))
+}
+
 type ClientOptions struct {
 	blockHeight *int64
 }
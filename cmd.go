@@ -2,18 +2,22 @@ package main
 
 import (
 	"context"
-	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/cosmos/cosmos-sdk/codec"
-	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
-	sdk "github.com/cosmos/cosmos-sdk/types"
-	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
-	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
 	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
@@ -25,32 +29,804 @@ func RootCmd() *cobra.Command {
 	}
 	cmd.AddCommand(
 		SummaryCmd(),
+		TimeSeriesCmd(),
+		CandlesCmd(),
+		TradersCmd(),
+		AddressCmd(),
+		PoolInvestorsCmd(),
+		DiffInvestorsCmd(),
+		ReconcileCmd(),
+		DiffCmd(),
+		ServeCmd(),
+		ExporterCmd(),
+		WatchCmd(),
 		ReadGenesisCmd(),
 		SearchBlockCmd(),
+		IndexCmd(),
 	)
 	return cmd
 }
 
-type PoolSummary struct {
-	ID           uint64
-	ReserveCoins [2]sdk.Coin
-	Swaps        [2]SwapSummary
+func SummaryCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var sourceFlags EventSourceFlags
+	var outFileName string
+	var format string
+	var pricingFlags PricingFlags
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Display short summary",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			switch format {
+			case FormatCSV, FormatJSON, FormatJSONL, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+			if err := pricingFlags.Validate(cfg.Pricing); err != nil {
+				return err
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			src, err := sourceFlags.Open(cfg, c)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			r, err := rangeFlags.Resolve(ctx, c, src)
+			if err != nil {
+				return err
+			}
+			beginHeight, endHeight := r.BeginHeight, r.EndHeight
+
+			fmt.Fprintln(os.Stderr, "loading liquidity pools")
+
+			pools, err := c.Pools(ctx, WithBlockHeight(endHeight))
+			if err != nil {
+				return fmt.Errorf("get pools: %w", err)
+			}
+
+			poolSummaries, err := LoadPoolReserves(ctx, c, pools, endHeight)
+			if err != nil {
+				return err
+			}
+
+			denomSet := make(map[string]struct{})
+			for _, ps := range poolSummaries {
+				for _, coin := range ps.ReserveCoins {
+					denomSet[coin.Denom] = struct{}{}
+				}
+			}
+
+			fmt.Fprintln(os.Stderr, "loading events")
+
+			swapRequesters, err := SummarizeEvents(ctx, src, poolSummaries, beginHeight, endHeight)
+			if err != nil {
+				return err
+			}
+
+			// The human readable header is only printed along with the table,
+			// so that it doesn't get in the way of machine readable formats.
+			header := io.Discard
+			if format == FormatTable {
+				header = os.Stderr
+			}
+			fmt.Fprintf(header, "Gravity DEX Summary (block height: %d)\n", endHeight)
+			fmt.Fprintf(header, "* from block %d (%s)\n", beginHeight, r.BeginTime.Format(time.RFC3339))
+			fmt.Fprintf(header, "* to block %d (%s)\n", endHeight, r.EndTime.Format(time.RFC3339))
+			fmt.Fprintf(header, "* %d kind(s) of token\n", len(denomSet))
+			fmt.Fprintf(header, "* %d swap trader(s)\n", len(swapRequesters))
+			var numDeposits, numWithdrawals int
+			for _, ps := range poolSummaries {
+				numDeposits += ps.Deposits.Count
+				numWithdrawals += ps.Withdrawals.Count
+			}
+			fmt.Fprintf(header, "* %d deposit(s)\n", numDeposits)
+			fmt.Fprintf(header, "* %d withdrawal(s)\n", numWithdrawals)
+
+			prices, unpricedDenoms, err := pricingFlags.Load(ctx, cfg.Pricing, poolSummaries)
+			if err != nil {
+				return err
+			}
+			if len(unpricedDenoms) > 0 {
+				fmt.Fprintf(header, "* %d denom(s) could not be priced: %s\n", len(unpricedDenoms), strings.Join(unpricedDenoms, ", "))
+			}
+			if prices != nil {
+				var tvl, swapVolume, fees float64
+				var numUnpriced int
+				for _, ps := range poolSummaries {
+					ps.Value = prices.PoolValue(ps)
+					if ps.Value == nil {
+						numUnpriced++
+						continue
+					}
+					tvl += ps.Value.Reserve
+					swapVolume += ps.Value.SwapVolume
+					fees += ps.Value.Fees
+				}
+				fmt.Fprintf(header, "* total value locked: $%s\n", formatUSD(tvl))
+				fmt.Fprintf(header, "* total swapped amount: $%s\n", formatUSD(swapVolume))
+				fmt.Fprintf(header, "* total fees paid: $%s\n", formatUSD(fees))
+				if numUnpriced > 0 {
+					fmt.Fprintf(header, "* %d pool(s) without prices\n", numUnpriced)
+				}
+			}
+
+			report := SummaryReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight:    beginHeight,
+					EndHeight:      endHeight,
+					BeginTime:      r.BeginTime,
+					EndTime:        r.EndTime,
+					GeneratedAt:    time.Now().UTC(),
+					UnpricedDenoms: unpricedDenoms,
+				},
+				Pools: poolSummaries,
+			}
+
+			if format == FormatTable {
+				outFileName = "-"
+			} else if !cmd.Flags().Changed("out") {
+				outFileName = "pools." + format
+			}
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteSummaryReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	rangeFlags.AddFlags(cmd)
+	sourceFlags.AddFlags(cmd)
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "pools.csv", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatCSV, "Output format; csv, json, jsonl or table")
+	pricingFlags.AddFlags(cmd)
+	return cmd
+}
+
+func TimeSeriesCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var sourceFlags EventSourceFlags
+	var bucketStr string
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "timeseries",
+		Short: "Summarize swaps per pool over time buckets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			switch format {
+			case FormatCSV, FormatJSON, FormatJSONL:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			bucket, err := ParseDuration(bucketStr)
+			if err != nil {
+				return fmt.Errorf("parse bucket: %w", err)
+			}
+			if bucket <= 0 {
+				return fmt.Errorf("bucket must be positive")
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			src, err := sourceFlags.Open(cfg, c)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			r, err := rangeFlags.Resolve(ctx, c, src)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, "loading liquidity pools")
+
+			pools, err := c.Pools(ctx, WithBlockHeight(r.EndHeight))
+			if err != nil {
+				return fmt.Errorf("get pools: %w", err)
+			}
+
+			fmt.Fprintln(os.Stderr, "loading events")
+
+			ts := NewTimeSeries(pools, bucket)
+			if err := src.IterateEvents(ctx, SwapEventTypes, r.BeginHeight, r.EndHeight, true, ts.Add); err != nil {
+				return fmt.Errorf("iterate events: %w", err)
+			}
+			points := ts.Points()
+			if len(points) == 0 {
+				fmt.Fprintln(os.Stderr, "no swap events found")
+				return nil
+			}
+
+			report := TimeSeriesReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight: r.BeginHeight,
+					EndHeight:   r.EndHeight,
+					BeginTime:   r.BeginTime,
+					EndTime:     r.EndTime,
+					GeneratedAt: time.Now().UTC(),
+				},
+				Bucket: bucket.String(),
+				Points: points,
+			}
+
+			if !cmd.Flags().Changed("out") {
+				outFileName = "timeseries." + format
+			}
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteTimeSeriesReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	rangeFlags.AddFlags(cmd)
+	sourceFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&bucketStr, "bucket", "1d", "Bucket size, such as 1d or 1h")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "timeseries.csv", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatCSV, "Output format; csv, json or jsonl")
+	return cmd
 }
 
-type SwapSummary struct {
-	OfferCoin     sdk.Coin
-	OfferCoinFee  sdk.Coin
-	DemandCoin    sdk.Coin
-	DemandCoinFee sdk.Coin
+func CandlesCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var sourceFlags EventSourceFlags
+	var intervalStr string
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "candles",
+		Short: "Build OHLCV candles per pool from executed swap prices",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			switch format {
+			case FormatCSV, FormatJSON, FormatJSONL:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			interval, err := ParseDuration(intervalStr)
+			if err != nil {
+				return fmt.Errorf("parse interval: %w", err)
+			}
+			if interval <= 0 {
+				return fmt.Errorf("interval must be positive")
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			src, err := sourceFlags.Open(cfg, c)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			r, err := rangeFlags.Resolve(ctx, c, src)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, "loading liquidity pools")
+
+			pools, err := c.Pools(ctx, WithBlockHeight(r.EndHeight))
+			if err != nil {
+				return fmt.Errorf("get pools: %w", err)
+			}
+
+			fmt.Fprintln(os.Stderr, "loading events")
+
+			cs := NewCandles(pools, interval)
+			if err := src.IterateEvents(ctx, SwapEventTypes, r.BeginHeight, r.EndHeight, true, cs.Add); err != nil {
+				return fmt.Errorf("iterate events: %w", err)
+			}
+
+			report := CandleReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight: r.BeginHeight,
+					EndHeight:   r.EndHeight,
+					BeginTime:   r.BeginTime,
+					EndTime:     r.EndTime,
+					GeneratedAt: time.Now().UTC(),
+				},
+				Interval: interval.String(),
+				Candles:  cs.Candles(),
+			}
+
+			if !cmd.Flags().Changed("out") {
+				outFileName = "candles." + format
+			}
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteCandleReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	rangeFlags.AddFlags(cmd)
+	sourceFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&intervalStr, "interval", "1h", "Candle interval, such as 15m, 1h or 1d")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "candles.csv", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatCSV, "Output format; csv, json or jsonl")
+	return cmd
+}
+
+func TradersCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var sourceFlags EventSourceFlags
+	var pricingFlags PricingFlags
+	var sortBy string
+	var limit int
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "traders",
+		Short: "Report swap activity per trader address",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			switch format {
+			case FormatCSV, FormatJSON, FormatJSONL, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			switch sortBy {
+			case TraderSortSwaps, TraderSortVolume, TraderSortFees, TraderSortPools, TraderSortFirst, TraderSortLast:
+			default:
+				return fmt.Errorf("unknown sort key: %s", sortBy)
+			}
+			if limit < 0 {
+				return fmt.Errorf("limit must not be negative")
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+			if err := pricingFlags.Validate(cfg.Pricing); err != nil {
+				return err
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			src, err := sourceFlags.Open(cfg, c)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			r, err := rangeFlags.Resolve(ctx, c, src)
+			if err != nil {
+				return err
+			}
+
+			var poolSummaries []*PoolSummary
+			if pricingFlags.Deriving(cfg.Pricing) {
+				fmt.Fprintln(os.Stderr, "loading liquidity pools")
+
+				pools, err := c.Pools(ctx, WithBlockHeight(r.EndHeight))
+				if err != nil {
+					return fmt.Errorf("get pools: %w", err)
+				}
+				poolSummaries, err = LoadPoolReserves(ctx, c, pools, r.EndHeight)
+				if err != nil {
+					return err
+				}
+			}
+			prices, unpricedDenoms, err := pricingFlags.Load(ctx, cfg.Pricing, poolSummaries)
+			if err != nil {
+				return err
+			}
+			if (sortBy == TraderSortVolume || sortBy == TraderSortFees) && prices == nil {
+				return fmt.Errorf("sorting by %s requires prices", sortBy)
+			}
+
+			fmt.Fprintln(os.Stderr, "loading events")
+
+			traders := NewTraders()
+			if err := src.IterateEvents(ctx, SwapEventTypes, r.BeginHeight, r.EndHeight, false, traders.Add); err != nil {
+				return fmt.Errorf("iterate events: %w", err)
+			}
+
+			summaries := traders.Traders()
+			if prices != nil {
+				for _, ts := range summaries {
+					ts.Value = prices.TraderValue(ts)
+				}
+			}
+			if err := SortTraders(summaries, sortBy); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "* %d swap trader(s)\n", len(summaries))
+			if limit > 0 && len(summaries) > limit {
+				summaries = summaries[:limit]
+			}
+
+			report := TraderReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight:    r.BeginHeight,
+					EndHeight:      r.EndHeight,
+					BeginTime:      r.BeginTime,
+					EndTime:        r.EndTime,
+					GeneratedAt:    time.Now().UTC(),
+					UnpricedDenoms: unpricedDenoms,
+				},
+				Traders: summaries,
+			}
+
+			if format == FormatTable {
+				outFileName = "-"
+			} else if !cmd.Flags().Changed("out") {
+				outFileName = "traders." + format
+			}
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteTraderReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	rangeFlags.AddFlags(cmd)
+	sourceFlags.AddFlags(cmd)
+	pricingFlags.AddFlags(cmd)
+	cmd.Flags().StringVarP(&sortBy, "sort", "s", TraderSortSwaps, "Sort key; swaps, volume, fees, pools, first or last")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Number of top traders to report, or 0 for all")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "traders.csv", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatCSV, "Output format; csv, json, jsonl or table")
+	return cmd
+}
+
+func AddressCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var sourceFlags EventSourceFlags
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "address [bech32]",
+		Short: "List the swaps, deposits and withdrawals of an address",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr := args[0]
+			if _, _, err := bech32.DecodeAndConvert(addr); err != nil {
+				return fmt.Errorf("invalid address: %w", err)
+			}
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			switch format {
+			case FormatJSON, FormatJSONL, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			src, err := sourceFlags.Open(cfg, c)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			r, err := rangeFlags.Resolve(ctx, c, src)
+			if err != nil {
+				return err
+			}
+
+			balances, err := c.AllBalances(ctx, addr, WithBlockHeight(r.EndHeight))
+			if err != nil {
+				return fmt.Errorf("get balances: %w", err)
+			}
+			pools, err := c.Pools(ctx, WithBlockHeight(r.EndHeight))
+			if err != nil {
+				return fmt.Errorf("get pools: %w", err)
+			}
+			holdings, err := LoadPoolCoinHoldings(ctx, c, balances, pools, r.EndHeight)
+			if err != nil {
+				return err
+			}
+
+			activities, err := SearchAddressRequests(ctx, c, addr, r.BeginHeight, r.EndHeight)
+			if err != nil {
+				return err
+			}
+
+			if err := src.IterateAddressEvents(ctx, addr, r.BeginHeight, r.EndHeight, func(evt LiquidityEvent, blockTime time.Time) error {
+				activities = append(activities, &AddressActivity{
+					Height: evt.GetHeight(),
+					Time:   blockTime,
+					Type:   evt.GetType(),
+					PoolID: evt.GetPoolID(),
+					Event:  evt,
+				})
+				return nil
+			}); err != nil {
+				return fmt.Errorf("iterate events: %w", err)
+			}
+			SortAddressActivities(activities)
+
+			report := AddressReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight: r.BeginHeight,
+					EndHeight:   r.EndHeight,
+					BeginTime:   r.BeginTime,
+					EndTime:     r.EndTime,
+					GeneratedAt: time.Now().UTC(),
+				},
+				Address:    addr,
+				Balances:   balances,
+				PoolCoins:  holdings,
+				Activities: activities,
+			}
+
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteAddressReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	rangeFlags.AddFlags(cmd)
+	sourceFlags.AddFlags(cmd)
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatTable, "Output format; table, json or jsonl")
+	return cmd
+}
+
+func PoolInvestorsCmd() *cobra.Command {
+	var height int64
+	var withInvestors bool
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "pool-investors",
+		Short: "Count pool investors at a block height from live state",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case FormatCSV, FormatJSON, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			if height == 0 {
+				height, err = c.LatestBlockHeight(ctx)
+				if err != nil {
+					return fmt.Errorf("get latest block height: %w", err)
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "loading balances at block %d\n", height)
+
+			snapshot, err := LoadInvestorSnapshot(ctx, c, height)
+			if err != nil {
+				return err
+			}
+
+			report := InvestorReport{
+				Height:       height,
+				NumInvestors: snapshot.NumInvestors(),
+				Pools:        snapshot.Stats(withInvestors),
+			}
+
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteInvestorReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&height, "height", 0, "Block height (defaults to the latest)")
+	cmd.Flags().BoolVar(&withInvestors, "investors", false, "Include every investor's holding in JSON output")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatTable, "Output format; table, csv or json")
+	return cmd
+}
+
+func DiffInvestorsCmd() *cobra.Command {
+	var details bool
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "diff-investors [from] [to]",
+		Short: "Compare pool investors between two genesis files or block heights",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case FormatCSV, FormatJSON, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+
+			cmd.SilenceUsage = true
+
+			ctx := context.Background()
+
+			var c *Client
+			snapshots := make([]*InvestorSnapshot, len(args))
+			for i, arg := range args {
+				height, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					gen, err := ReadGenesis(arg)
+					if err != nil {
+						return err
+					}
+					pools, err := gen.Pools()
+					if err != nil {
+						return err
+					}
+					snapshots[i] = gen.InvestorSnapshot(pools)
+					continue
+				}
+				if c == nil {
+					cfg, err := ReadClientConfig("config.toml")
+					if err != nil {
+						return fmt.Errorf("read client config: %w", err)
+					}
+					c, err = NewClient(cfg)
+					if err != nil {
+						return fmt.Errorf("new client: %w", err)
+					}
+					defer c.Close()
+				}
+				fmt.Fprintf(os.Stderr, "loading balances at block %d\n", height)
+				snapshots[i], err = LoadInvestorSnapshot(ctx, c, height)
+				if err != nil {
+					return err
+				}
+			}
+
+			report := InvestorDiffReport{
+				From:  args[0],
+				To:    args[1],
+				Pools: DiffInvestors(snapshots[0], snapshots[1]),
+			}
+
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteInvestorDiffReport(w, format, report, details)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&details, "details", false, "List every changed investor in table output")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatTable, "Output format; table, csv or json")
+	return cmd
 }
 
-func SummaryCmd() *cobra.Command {
-	var beginHeight, endHeight int64
+func ReconcileCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var sourceFlags EventSourceFlags
 	var outFileName string
+	var format string
 	cmd := &cobra.Command{
-		Use:   "summary",
-		Short: "Display short summary",
+		Use:   "reconcile",
+		Short: "Verify pool reserves against replayed swap, deposit and withdrawal events",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			switch format {
+			case FormatJSON, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
 			cmd.SilenceUsage = true
 
 			cfg, err := ReadClientConfig("config.toml")
@@ -66,158 +842,194 @@ func SummaryCmd() *cobra.Command {
 
 			ctx := context.Background()
 
-			if endHeight == 0 {
-				h, err := c.LatestBlockHeight(ctx)
-				if err != nil {
-					return fmt.Errorf("get latest block height: %w", err)
-				}
-				endHeight = h
+			src, err := sourceFlags.Open(cfg, c)
+			if err != nil {
+				return err
 			}
+			defer src.Close()
 
-			if beginHeight > endHeight {
-				return fmt.Errorf("begin height must be less or equal than end height")
+			r, err := rangeFlags.Resolve(ctx, c, src)
+			if err != nil {
+				return err
 			}
+			beginHeight, endHeight := r.BeginHeight, r.EndHeight
 
-			fmt.Println("loading liquidity pools")
-
-			pools, err := c.Pools(ctx, WithBlockHeight(endHeight))
+			// Pools created after the begin height have no known starting
+			// reserves, so only pools existing at the begin height are replayed.
+			pools, err := c.Pools(ctx, WithBlockHeight(beginHeight))
 			if err != nil {
 				return fmt.Errorf("get pools: %w", err)
 			}
 
-			summaries := make(map[uint64]*PoolSummary)
-			denomSet := make(map[string]struct{})
-			swapRequesters := make(map[string]struct{})
+			fmt.Fprintf(os.Stderr, "loading reserves at block %d\n", beginHeight)
 
-			bar := progressbar.Default(int64(len(pools)))
+			beginReserves, err := LoadPoolReserves(ctx, c, pools, beginHeight)
+			if err != nil {
+				return err
+			}
 
-			for _, pool := range pools {
-				summaries[pool.Id] = &PoolSummary{ID: pool.Id}
-				for i, denom := range pool.ReserveCoinDenoms {
-					balance, err := c.Balance(ctx, pool.ReserveAccountAddress, denom, WithBlockHeight(endHeight))
-					if err != nil {
-						return fmt.Errorf("get balance: %w", err)
-					}
-					summaries[pool.Id].ReserveCoins[i] = balance
-					summaries[pool.Id].Swaps[i].OfferCoin = sdk.NewCoin(denom, sdk.ZeroInt())
-					summaries[pool.Id].Swaps[i].OfferCoinFee = sdk.NewCoin(denom, sdk.ZeroInt())
-					summaries[pool.Id].Swaps[i].DemandCoin = sdk.NewCoin(pool.ReserveCoinDenoms[1-i], sdk.ZeroInt())
-					summaries[pool.Id].Swaps[i].DemandCoinFee = sdk.NewCoin(pool.ReserveCoinDenoms[1-i], sdk.ZeroInt())
-					denomSet[denom] = struct{}{}
+			replay := NewReserveReplay(pools, beginReserves)
+
+			if endHeight > beginHeight {
+				fmt.Fprintln(os.Stderr, "replaying events")
+
+				if err := src.IterateEvents(ctx, EndBlockEventTypes, beginHeight+1, endHeight, false, replay.Add); err != nil {
+					return fmt.Errorf("iterate events: %w", err)
 				}
-				_ = bar.Add(1)
 			}
 
-			fmt.Println("loading events")
+			fmt.Fprintf(os.Stderr, "loading reserves at block %d\n", endHeight)
 
-			heights, err := c.SearchBlockHeights(
-				ctx,
-				fmt.Sprintf(`swap_transacted.pool_id EXISTS AND block.height >= %d AND block.height <= %d`, beginHeight, endHeight),
-			)
+			endReserves, err := LoadPoolReserves(ctx, c, pools, endHeight)
 			if err != nil {
-				return fmt.Errorf("search block heights: %w", err)
+				return err
 			}
-			if len(heights) == 0 {
-				fmt.Println("no swap events found")
-				return nil
+
+			results, err := replay.Reconcile(ctx, c, endReserves, beginHeight, endHeight)
+			if err != nil {
+				return err
+			}
+
+			report := ReconcileReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight: beginHeight,
+					EndHeight:   endHeight,
+					BeginTime:   r.BeginTime,
+					EndTime:     r.EndTime,
+					GeneratedAt: time.Now().UTC(),
+				},
+				Pools: results,
+			}
+			for _, pr := range results {
+				if !pr.Matches {
+					report.NumMismatches++
+				}
+			}
+
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteReconcileReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+
+			return nil
+		},
+	}
+	rangeFlags.AddFlags(cmd)
+	sourceFlags.AddFlags(cmd)
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatTable, "Output format; table or json")
+	return cmd
+}
+
+func DiffCmd() *cobra.Command {
+	var rangeFlags HeightRangeFlags
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare pools, reserves, pool coin supplies and prices between two heights",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case FormatJSON, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+			if err := rangeFlags.Validate(cmd); err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+
+			r, err := rangeFlags.Resolve(ctx, c, nil)
+			if err != nil {
+				return err
 			}
 
-			bar = progressbar.Default(int64(len(heights)))
+			var pools [2][]liquiditytypes.Pool
+			var states [2]map[uint64]PoolState
+			for i, height := range []int64{r.BeginHeight, r.EndHeight} {
+				fmt.Fprintf(os.Stderr, "loading pools at block %d\n", height)
 
-			for _, height := range heights {
-				events, err := c.EndBlockEvents(ctx, height)
+				pools[i], err = c.Pools(ctx, WithBlockHeight(height))
 				if err != nil {
-					return fmt.Errorf("get end block events: %w", err)
+					return fmt.Errorf("get pools: %w", err)
 				}
-				for _, event := range events {
-					if event.Type == liquiditytypes.EventTypeSwapTransacted {
-						ste, err := NewSwapTransactedEvent(event)
-						if err != nil {
-							return fmt.Errorf("new swap_transacted event: %w", err)
-						}
-						if ste.Success {
-							ps, ok := summaries[ste.PoolID]
-							if !ok {
-								return fmt.Errorf("pool id not found: %d", ste.PoolID)
-							}
-							var i int
-							if ste.ExchangedOfferCoin.Denom == ps.Swaps[0].OfferCoin.Denom {
-								i = 0
-							} else {
-								i = 1
-							}
-							ps.Swaps[i].OfferCoin = ps.Swaps[i].OfferCoin.Add(ste.ExchangedOfferCoin)
-							ps.Swaps[i].OfferCoinFee = ps.Swaps[i].OfferCoinFee.Add(ste.ExchangedOfferCoinFee)
-							ps.Swaps[i].DemandCoin = ps.Swaps[i].DemandCoin.Add(ste.ExchangedDemandCoin)
-							ps.Swaps[i].DemandCoinFee = ps.Swaps[i].DemandCoinFee.Add(ste.ExchangedDemandCoinFee)
-							swapRequesters[ste.SwapRequesterAddress] = struct{}{}
-						}
-					}
+				states[i], err = LoadPoolStates(ctx, c, pools[i], height)
+				if err != nil {
+					return err
 				}
-				_ = bar.Add(1)
 			}
 
-			fmt.Printf("Gravity DEX Summary (block height: %d)\n", endHeight)
-			fmt.Printf("* %d kind(s) of token\n", len(denomSet))
-			fmt.Printf("* %d swap trader(s)\n", len(swapRequesters))
-
-			outFile, err := os.Create(outFileName)
-			if err != nil {
-				return fmt.Errorf("create output file: %w", err)
-			}
-			defer outFile.Close()
-
-			csvWriter := csv.NewWriter(outFile)
-
-			records := [][]string{{
-				"id", "x_denom", "y_denom", "x", "y",
-				"offer_x", "offer_x_fee", "demand_y", "demand_y_fee",
-				"offer_y", "offer_y_fee", "demand_x", "demand_x_fee",
-			}}
-			for _, pool := range pools {
-				ps := summaries[pool.Id]
-				records = append(records, []string{
-					strconv.FormatUint(ps.ID, 10),
-					ps.ReserveCoins[0].Denom,
-					ps.ReserveCoins[1].Denom,
-					ps.ReserveCoins[0].Amount.String(),
-					ps.ReserveCoins[1].Amount.String(),
-					ps.Swaps[0].OfferCoin.Amount.String(),
-					ps.Swaps[0].OfferCoinFee.Amount.String(),
-					ps.Swaps[0].DemandCoin.Amount.String(),
-					ps.Swaps[0].DemandCoinFee.Amount.String(),
-					ps.Swaps[1].OfferCoin.Amount.String(),
-					ps.Swaps[1].OfferCoinFee.Amount.String(),
-					ps.Swaps[1].DemandCoin.Amount.String(),
-					ps.Swaps[1].DemandCoinFee.Amount.String(),
-				})
+			report := PoolDiffReport{
+				SummaryMetadata: SummaryMetadata{
+					BeginHeight: r.BeginHeight,
+					EndHeight:   r.EndHeight,
+					BeginTime:   r.BeginTime,
+					EndTime:     r.EndTime,
+					GeneratedAt: time.Now().UTC(),
+				},
+				Pools: DiffPools(pools[0], states[0], pools[1], states[1]),
 			}
 
-			if err := csvWriter.WriteAll(records); err != nil {
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WritePoolDiffReport(w, format, report)
+			}); err != nil {
 				return fmt.Errorf("write output: %w", err)
 			}
 
 			return nil
 		},
 	}
-	cmd.Flags().Int64VarP(&beginHeight, "begin", "b", 1, "Begin block height")
-	cmd.Flags().Int64VarP(&endHeight, "end", "e", 0, "End block height")
-	cmd.Flags().StringVarP(&outFileName, "out", "o", "pools.csv", "Output file name")
+	rangeFlags.AddFlags(cmd)
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatTable, "Output format; table or json")
 	return cmd
 }
 
-func ReadGenesisCmd() *cobra.Command {
+func ServeCmd() *cobra.Command {
+	var sourceFlags EventSourceFlags
+	var pricingFlags PricingFlags
+	var addr string
+	var cacheSize int
+	var maxComputations int
+	var shutdownTimeout time.Duration
 	cmd := &cobra.Command{
-		Use:   "read-genesis [file]",
-		Short: "Read genesis file and extract summary",
-		Args:  cobra.ExactArgs(1),
+		Use:   "serve",
+		Short: "Serve statistics over an HTTP API",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := sourceFlags.Validate(); err != nil {
+				return err
+			}
+			if cacheSize < 0 {
+				return fmt.Errorf("cache size must not be negative")
+			}
+			if maxComputations <= 0 {
+				return fmt.Errorf("max computations must be positive")
+			}
+
 			cmd.SilenceUsage = true
 
 			cfg, err := ReadClientConfig("config.toml")
 			if err != nil {
 				return fmt.Errorf("read client config: %w", err)
 			}
+			if err := pricingFlags.Validate(cfg.Pricing); err != nil {
+				return err
+			}
 
 			c, err := NewClient(cfg)
 			if err != nil {
@@ -225,57 +1037,264 @@ func ReadGenesisCmd() *cobra.Command {
 			}
 			defer c.Close()
 
-			ctx := context.Background()
-
-			pools, err := c.Pools(ctx)
+			src, err := sourceFlags.Open(cfg, c)
 			if err != nil {
-				return fmt.Errorf("get pools: %w", err)
+				return err
+			}
+			defer src.Close()
+
+			// Computations are cancelled on shutdown, so that long ones don't
+			// hold it up until the timeout.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			server := NewServer(ctx, c, src, cfg.Pricing, pricingFlags, cacheSize, maxComputations)
+			srv := &http.Server{Addr: addr, Handler: server.Handler()}
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- srv.ListenAndServe()
+			}()
+			fmt.Printf("serving on %s\n", addr)
+
+			select {
+			case err := <-errCh:
+				return fmt.Errorf("serve: %w", err)
+			case <-ctx.Done():
+			}
+
+			fmt.Println("shutting down")
+
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancelShutdown()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("shutdown: %w", err)
+			}
+
+			return nil
+		},
+	}
+	sourceFlags.AddFlags(cmd)
+	pricingFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().IntVar(&cacheSize, "cache-size", 128, "Number of responses to cache")
+	cmd.Flags().IntVar(&maxComputations, "max-computations", 2, "Number of responses computed at once; others wait")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "Time to wait for requests in flight on shutdown")
+	return cmd
+}
+
+func ExporterCmd() *cobra.Command {
+	var addr string
+	var beginHeight int64
+	var interval time.Duration
+	var maxBlocks int64
+	var concurrency int
+	cmd := &cobra.Command{
+		Use:   "exporter",
+		Short: "Export pool and swap metrics for Prometheus",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("interval must be positive")
+			}
+			if maxBlocks <= 0 {
+				return fmt.Errorf("max blocks must be positive")
 			}
 
-			poolCoinDenomSet := make(map[string]uint64) // (pool coin denom) => (pool id)
-			for _, pool := range pools {
-				poolCoinDenomSet[pool.PoolCoinDenom] = pool.Id
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
 			}
 
-			appState, _, err := genutiltypes.GenesisStateFromGenFile(args[0])
+			c, err := NewClient(cfg)
 			if err != nil {
-				return fmt.Errorf("genesis state from file: %w", err)
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			if concurrency == 0 {
+				concurrency = cfg.Concurrency
 			}
+			exporter := NewExporter(c, beginHeight, concurrency, maxBlocks)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(exporter.Collectors()...)
 
-			genState := banktypes.GetGenesisStateFromAppState(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), appState)
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+			srv := &http.Server{Addr: addr, Handler: mux}
 
-			numPoolInvestors := make(map[uint64]int) // (pool id) => (num pool investors)
-			totalPoolInvestors := make(map[string]struct{})
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
 
-			for _, balance := range genState.Balances {
-				for poolCoinDenom, poolID := range poolCoinDenomSet {
-					if balance.Coins.AmountOf(poolCoinDenom).IsPositive() {
-						numPoolInvestors[poolID]++
-						totalPoolInvestors[balance.Address] = struct{}{}
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- srv.ListenAndServe()
+			}()
+			fmt.Printf("serving metrics on %s/metrics\n", addr)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if err := exporter.Poll(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("poll: %v", err)
+				}
+				select {
+				case err := <-errCh:
+					return fmt.Errorf("serve: %w", err)
+				case <-ctx.Done():
+					fmt.Println("shutting down")
+					shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+					defer cancel()
+					if err := srv.Shutdown(shutdownCtx); err != nil {
+						return fmt.Errorf("shutdown: %w", err)
 					}
+					return nil
+				case <-ticker.C:
 				}
 			}
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "Address to serve metrics on")
+	cmd.Flags().Int64VarP(&beginHeight, "begin", "b", 0, "Block height to start counting swaps from (defaults to the latest)")
+	cmd.Flags().DurationVar(&interval, "interval", 10*time.Second, "Polling interval")
+	cmd.Flags().Int64Var(&maxBlocks, "max-blocks", 1000, "Maximum number of blocks to process per poll")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 0, "Number of concurrent block results requests (defaults to config)")
+	return cmd
+}
+
+func WatchCmd() *cobra.Command {
+	var beginHeight int64
+	var idleTimeout time.Duration
+	var concurrency int
+	var outFileName string
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Follow new blocks and print liquidity events as JSON lines",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if idleTimeout <= 0 {
+				return fmt.Errorf("idle timeout must be positive")
+			}
+
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if concurrency == 0 {
+				concurrency = cfg.Concurrency
+			}
+			watcher := NewWatcher(c, beginHeight, concurrency, idleTimeout)
+
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				enc := json.NewEncoder(w)
+				return watcher.Run(ctx, func(evt LiquidityEvent) error {
+					return enc.Encode(evt)
+				})
+			}); err != nil {
+				return fmt.Errorf("watch: %w", err)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().Int64VarP(&beginHeight, "begin", "b", 0, "Block height to start from (defaults to after the latest)")
+	cmd.Flags().DurationVar(&idleTimeout, "idle-timeout", time.Minute, "Time without new blocks after which to reconnect")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 0, "Number of concurrent block results requests (defaults to config)")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	return cmd
+}
+
+func ReadGenesisCmd() *cobra.Command {
+	var withInvestors bool
+	var fromNode bool
+	var outFileName string
+	var format string
+	cmd := &cobra.Command{
+		Use:   "read-genesis [file]",
+		Short: "Read genesis file and extract summary",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case FormatJSON, FormatTable:
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+
+			cmd.SilenceUsage = true
+
+			gen, err := ReadGenesis(args[0])
+			if err != nil {
+				return err
+			}
+
+			var pools []liquiditytypes.Pool
+			if fromNode {
+				pools, err = loadLatestPools()
+			} else {
+				pools, err = gen.Pools()
+			}
+			if err != nil {
+				return err
+			}
 
-			fmt.Println("number of pool investors")
-			fmt.Println("========================")
-			for _, pool := range pools {
-				fmt.Printf("pool %d: %d\n", pool.Id, numPoolInvestors[pool.Id])
+			report := NewGenesisReport(gen, pools, withInvestors)
+
+			if err := writeOutput(outFileName, func(w io.Writer) error {
+				return WriteGenesisReport(w, format, report)
+			}); err != nil {
+				return fmt.Errorf("write output: %w", err)
 			}
-			fmt.Printf("total: %d\n", len(totalPoolInvestors))
 
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&withInvestors, "investors", false, "Include every investor's holding and share")
+	cmd.Flags().BoolVar(&fromNode, "node", false, "Get pools from the node instead of the genesis file")
+	cmd.Flags().StringVarP(&outFileName, "out", "o", "-", "Output file name, or - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", FormatTable, "Output format; table or json")
 	return cmd
 }
 
+// loadLatestPools gets the pools at the latest height from the node
+// configured in config.toml.
+func loadLatestPools() ([]liquiditytypes.Pool, error) {
+	cfg, err := ReadClientConfig("config.toml")
+	if err != nil {
+		return nil, fmt.Errorf("read client config: %w", err)
+	}
+
+	c, err := NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new client: %w", err)
+	}
+	defer c.Close()
+
+	pools, err := c.Pools(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("get pools: %w", err)
+	}
+	return pools, nil
+}
+
 func SearchBlockCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search-block [time]",
 		Short: "Search block heights for specific time",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			t, err := time.Parse(time.RFC3339, args[0])
+			t, err := ParseTime(args[0], time.Now())
 			if err != nil {
 				return fmt.Errorf("parse time: %w", err)
 			}
@@ -312,3 +1331,113 @@ func SearchBlockCmd() *cobra.Command {
 	}
 	return cmd
 }
+
+func IndexCmd() *cobra.Command {
+	var beginHeight, endHeight int64
+	var dbFileName string
+	var concurrency int
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Index liquidity end block events into a local database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			cfg, err := ReadClientConfig("config.toml")
+			if err != nil {
+				return fmt.Errorf("read client config: %w", err)
+			}
+
+			c, err := NewClient(cfg)
+			if err != nil {
+				return fmt.Errorf("new client: %w", err)
+			}
+			defer c.Close()
+
+			s, err := OpenStore(dbFileName)
+			if err != nil {
+				return fmt.Errorf("open store: %w", err)
+			}
+			defer s.Close()
+
+			ctx := context.Background()
+
+			firstHeight, lastHeight, ok, err := s.IndexedRange()
+			if err != nil {
+				return fmt.Errorf("get indexed range: %w", err)
+			}
+			if ok {
+				if cmd.Flags().Changed("begin") && (beginHeight < firstHeight || beginHeight > lastHeight+1) {
+					return fmt.Errorf("store covers heights %d-%d; can only resume from %d", firstHeight, lastHeight, lastHeight+1)
+				}
+				beginHeight = lastHeight + 1
+				fmt.Printf("resuming from block height %d\n", beginHeight)
+			} else {
+				earliestHeight, err := c.EarliestBlockHeight(ctx)
+				if err != nil {
+					return fmt.Errorf("get earliest block height: %w", err)
+				}
+				if beginHeight < earliestHeight {
+					beginHeight = earliestHeight
+				}
+				if err := s.StartIndexing(beginHeight); err != nil {
+					return fmt.Errorf("start indexing: %w", err)
+				}
+			}
+
+			if endHeight == 0 {
+				h, err := c.LatestBlockHeight(ctx)
+				if err != nil {
+					return fmt.Errorf("get latest block height: %w", err)
+				}
+				endHeight = h
+			}
+
+			if beginHeight > endHeight {
+				fmt.Println("already up to date")
+				return nil
+			}
+
+			fmt.Println("searching events")
+
+			heights, err := c.SearchEventBlockHeights(ctx, EndBlockEventTypes, beginHeight, endHeight)
+			if err != nil {
+				return fmt.Errorf("search block heights: %w", err)
+			}
+
+			if concurrency == 0 {
+				concurrency = cfg.Concurrency
+			}
+
+			fmt.Println("indexing events")
+
+			bar := progressbar.Default(int64(len(heights)))
+
+			if err := c.IterateBlockEvents(ctx, heights, concurrency, func(block BlockEvents) error {
+				evts, err := DecodeEvents(block.Height, block.Events)
+				if err != nil {
+					return err
+				}
+				if err := s.PutBlock(block.Height, block.Time, evts); err != nil {
+					return fmt.Errorf("put block %d: %w", block.Height, err)
+				}
+				_ = bar.Add(1)
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			if err := s.SetLastHeight(endHeight); err != nil {
+				return fmt.Errorf("set last height: %w", err)
+			}
+
+			fmt.Printf("indexed %d block(s) up to height %d\n", len(heights), endHeight)
+
+			return nil
+		},
+	}
+	cmd.Flags().Int64VarP(&beginHeight, "begin", "b", 1, "Begin block height")
+	cmd.Flags().Int64VarP(&endHeight, "end", "e", 0, "End block height")
+	cmd.Flags().StringVar(&dbFileName, "db", "events.db", "Event database file name")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 0, "Number of concurrent block results requests (defaults to config)")
+	return cmd
+}
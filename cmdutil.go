@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+)
+
+// HeightRange is a resolved range of blocks.
+type HeightRange struct {
+	BeginHeight int64
+	EndHeight   int64
+	BeginTime   time.Time
+	EndTime     time.Time
+}
+
+// HeightRangeFlags select a range of blocks either by height or by time.
+type HeightRangeFlags struct {
+	BeginHeight int64
+	EndHeight   int64
+	Since       string
+	Until       string
+
+	since, until time.Time
+	beginSet     bool
+}
+
+func (f *HeightRangeFlags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64VarP(&f.BeginHeight, "begin", "b", 1, "Begin block height")
+	cmd.Flags().Int64VarP(&f.EndHeight, "end", "e", 0, "End block height")
+	cmd.Flags().StringVar(&f.Since, "since", "", "Begin time, as RFC3339 or a duration before now such as 7d")
+	cmd.Flags().StringVar(&f.Until, "until", "", "End time, as RFC3339 or a duration before now such as 1d")
+}
+
+// Validate checks the flags and parses the given times.
+func (f *HeightRangeFlags) Validate(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("begin") && f.Since != "" {
+		return fmt.Errorf("--begin and --since are mutually exclusive")
+	}
+	if cmd.Flags().Changed("end") && f.Until != "" {
+		return fmt.Errorf("--end and --until are mutually exclusive")
+	}
+	f.beginSet = cmd.Flags().Changed("begin")
+	now := time.Now()
+	if f.Since != "" {
+		t, err := ParseTime(f.Since, now)
+		if err != nil {
+			return fmt.Errorf("parse since: %w", err)
+		}
+		f.since = t
+	}
+	if f.Until != "" {
+		t, err := ParseTime(f.Until, now)
+		if err != nil {
+			return fmt.Errorf("parse until: %w", err)
+		}
+		f.until = t
+	}
+	return nil
+}
+
+// Resolve resolves the flags into block heights and times. Times are
+// resolved to heights using the node, the end height defaults to the latest
+// height of src, or of the node if src is nil, and the default begin height
+// is raised to the node's earliest height. A *BeforeHistoryError is returned
+// if a begin height or time is given before the node's history.
+func (f *HeightRangeFlags) Resolve(ctx context.Context, c *Client, src EventSource) (HeightRange, error) {
+	r := HeightRange{BeginHeight: f.BeginHeight, EndHeight: f.EndHeight}
+
+	if !f.since.IsZero() {
+		h, err := c.SearchBlockHeightByTime(ctx, f.since)
+		if err != nil {
+			return HeightRange{}, fmt.Errorf("search begin height: %w", err)
+		}
+		r.BeginHeight = h
+	}
+	if !f.until.IsZero() {
+		h, err := c.SearchLastBlockHeightByTime(ctx, f.until)
+		if err != nil {
+			return HeightRange{}, fmt.Errorf("search end height: %w", err)
+		}
+		r.EndHeight = h
+	}
+
+	if r.EndHeight == 0 {
+		var h int64
+		var err error
+		if src != nil {
+			h, err = src.LatestHeight(ctx)
+		} else {
+			h, err = c.LatestBlockHeight(ctx)
+		}
+		if err != nil {
+			return HeightRange{}, fmt.Errorf("get latest block height: %w", err)
+		}
+		r.EndHeight = h
+	}
+
+	earliestHeight, err := c.EarliestBlockHeight(ctx)
+	if err != nil {
+		return HeightRange{}, fmt.Errorf("get earliest block height: %w", err)
+	}
+	if r.BeginHeight < earliestHeight {
+		if f.beginSet {
+			earliestTime, err := c.BlockTime(ctx, earliestHeight)
+			if err != nil {
+				return HeightRange{}, fmt.Errorf("get earliest block time: %w", err)
+			}
+			return HeightRange{}, &BeforeHistoryError{Height: r.BeginHeight, EarliestBlockHeight: earliestHeight, EarliestBlockTime: earliestTime}
+		}
+		r.BeginHeight = earliestHeight
+	}
+
+	if r.BeginHeight > r.EndHeight {
+		return HeightRange{}, fmt.Errorf("begin height must be less or equal than end height")
+	}
+
+	r.BeginTime, err = c.BlockTime(ctx, r.BeginHeight)
+	if err != nil {
+		return HeightRange{}, fmt.Errorf("get begin block time: %w", err)
+	}
+	r.EndTime, err = c.BlockTime(ctx, r.EndHeight)
+	if err != nil {
+		return HeightRange{}, fmt.Errorf("get end block time: %w", err)
+	}
+
+	return r, nil
+}
+
+// EventSourceFlags select where liquidity events are read from.
+type EventSourceFlags struct {
+	Source      string
+	DBFileName  string
+	Concurrency int
+}
+
+func (f *EventSourceFlags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.Source, "source", "node", "Event source; node or store")
+	cmd.Flags().StringVar(&f.DBFileName, "db", "events.db", "Event database file name, used with --source=store")
+	cmd.Flags().IntVarP(&f.Concurrency, "concurrency", "c", 0, "Number of concurrent block results requests (defaults to config)")
+}
+
+func (f *EventSourceFlags) Validate() error {
+	if f.Source != "node" && f.Source != "store" {
+		return fmt.Errorf("unknown source: %s", f.Source)
+	}
+	return nil
+}
+
+// Open opens the selected event source.
+func (f *EventSourceFlags) Open(cfg ClientConfig, c *Client) (EventSource, error) {
+	switch f.Source {
+	case "store":
+		s, err := OpenStore(f.DBFileName)
+		if err != nil {
+			return nil, fmt.Errorf("open store: %w", err)
+		}
+		return NewStoreEventSource(s), nil
+	default:
+		concurrency := f.Concurrency
+		if concurrency == 0 {
+			concurrency = cfg.Concurrency
+		}
+		return NewNodeEventSource(c, concurrency), nil
+	}
+}
+
+// PricingFlags override the pricing config.
+type PricingFlags struct {
+	Source string
+	Derive bool
+}
+
+func (f *PricingFlags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.Source, "prices", "", "Price file or URL for USD values (defaults to config)")
+	cmd.Flags().BoolVar(&f.Derive, "derive-prices", false, "Price denoms without a price from pool reserves")
+}
+
+// Validate checks that prices are only derived along with a price source,
+// whether derivation is enabled by the flags or by cfg.
+func (f *PricingFlags) Validate(cfg PricingConfig) error {
+	if (f.Derive || cfg.Derive) && f.Source == "" && cfg.Source == "" {
+		return fmt.Errorf("deriving prices requires a price source; set --prices or pricing.source in the config")
+	}
+	return nil
+}
+
+// Load loads prices as configured by cfg and the flags, deriving missing
+// prices from the reserves of pools if requested. It returns nil prices if
+// no price source is configured, and the denoms that could not be priced
+// when prices are derived.
+func (f *PricingFlags) Load(ctx context.Context, cfg PricingConfig, pools []*PoolSummary) (*Prices, []string, error) {
+	if err := f.Validate(cfg); err != nil {
+		return nil, nil, err
+	}
+	if f.Source != "" {
+		cfg.Source = f.Source
+	}
+	if f.Derive {
+		cfg.Derive = true
+	}
+	if cfg.Source == "" {
+		return nil, nil, nil
+	}
+	prices, err := LoadPrices(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load prices: %w", err)
+	}
+	var unpricedDenoms []string
+	if cfg.Derive {
+		unpricedDenoms = prices.DerivePrices(pools, cfg.Anchors)
+	}
+	return prices, unpricedDenoms, nil
+}
+
+// Deriving reports whether missing prices will be derived from pool reserves.
+func (f *PricingFlags) Deriving(cfg PricingConfig) bool {
+	return (f.Source != "" || cfg.Source != "") && (f.Derive || cfg.Derive)
+}
+
+// LoadPoolReserves returns summaries of pools holding only their reserves
+// at height.
+func LoadPoolReserves(ctx context.Context, c *Client, pools []liquiditytypes.Pool, height int64) ([]*PoolSummary, error) {
+	bar := progressbar.Default(int64(len(pools)))
+	return loadPoolReserves(ctx, c, pools, height, func() { _ = bar.Add(1) })
+}
+
+func loadPoolReserves(ctx context.Context, c *Client, pools []liquiditytypes.Pool, height int64, progress func()) ([]*PoolSummary, error) {
+	var summaries []*PoolSummary
+	for _, pool := range pools {
+		ps := NewPoolSummary(pool)
+		for i, denom := range pool.ReserveCoinDenoms {
+			balance, err := c.Balance(ctx, pool.ReserveAccountAddress, denom, WithBlockHeight(height))
+			if err != nil {
+				return nil, fmt.Errorf("get balance: %w", err)
+			}
+			ps.ReserveCoins[i] = balance
+		}
+		summaries = append(summaries, ps)
+		progress()
+	}
+	return summaries, nil
+}
+
+// LoadInvestorSnapshot scans all balances at height for holdings of the
+// pools existing at that height.
+func LoadInvestorSnapshot(ctx context.Context, c *Client, height int64) (*InvestorSnapshot, error) {
+	pools, err := c.Pools(ctx, WithBlockHeight(height))
+	if err != nil {
+		return nil, fmt.Errorf("get pools: %w", err)
+	}
+	snapshot := NewInvestorSnapshot(pools)
+	if err := c.IterateAllBalances(ctx, func(addr sdk.AccAddress, coin sdk.Coin) error {
+		snapshot.AddBalance(addr.String(), coin)
+		return nil
+	}, WithBlockHeight(height)); err != nil {
+		return nil, fmt.Errorf("iterate balances: %w", err)
+	}
+	return snapshot, nil
+}
@@ -2,15 +2,31 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
-var DefaultClientConfig = ClientConfig{}
+var DefaultClientConfig = ClientConfig{
+	Concurrency:    1,
+	BlockTimeIndex: "blocktimes.csv",
+	Retry: RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		CallTimeout:    time.Minute,
+	},
+}
 
 type ClientConfig struct {
-	GRPC GRPCConfig
-	RPC  RPCConfig
+	GRPC        GRPCConfig
+	RPC         RPCConfig
+	Retry       RetryConfig
+	Concurrency int
+	// BlockTimeIndex is the path of the on-disk block time index. The index
+	// is tied to the chain ID of the node. An empty path disables the index.
+	BlockTimeIndex string
+	Pricing        PricingConfig
 }
 
 type GRPCConfig struct {
@@ -22,6 +38,33 @@ type GRPCConfig struct {
 type RPCConfig struct {
 	URL   string
 	Token string
+	// EarliestBlockHeight overrides the earliest block height reported by
+	// the node, for nodes whose history starts with missing blocks.
+	EarliestBlockHeight int64
+}
+
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	CallTimeout    time.Duration
+}
+
+type PricingConfig struct {
+	// Source is the URL or file path prices are loaded from.
+	// An empty source disables pricing.
+	Source    string
+	IBCTraces []IBCTrace
+	// Derive enables pricing denoms without a price from pool reserves,
+	// starting from Anchors, or from every priced denom if Anchors is empty.
+	Derive  bool
+	Anchors []string
+}
+
+// IBCTrace maps an IBC denom to the base denom it is priced by.
+type IBCTrace struct {
+	Denom     string
+	BaseDenom string
 }
 
 func ReadClientConfig(path string) (ClientConfig, error) {
@@ -9,12 +9,117 @@ import (
 	abcitypes "github.com/tendermint/tendermint/abci/types"
 )
 
+// LiquidityEvent is a decoded liquidity module event.
+type LiquidityEvent interface {
+	GetHeight() int64
+	GetPoolID() uint64
+	GetType() string
+	GetAttributes() map[string]string
+	setHeight(height int64)
+}
+
+// EventDecoder decodes an event of a specific type.
+type EventDecoder func(event abcitypes.Event) (LiquidityEvent, error)
+
+// EventDecoders maps event types to their decoders.
+// Decoded events are pointers, such as *SwapTransactedEvent.
+var EventDecoders = map[string]EventDecoder{
+	liquiditytypes.EventTypeCreatePool: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewCreatePoolEvent(event)
+		return &evt, err
+	},
+	liquiditytypes.EventTypeDepositWithinBatch: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewDepositWithinBatchEvent(event)
+		return &evt, err
+	},
+	liquiditytypes.EventTypeWithdrawWithinBatch: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewWithdrawWithinBatchEvent(event)
+		return &evt, err
+	},
+	liquiditytypes.EventTypeSwapWithinBatch: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewSwapWithinBatchEvent(event)
+		return &evt, err
+	},
+	liquiditytypes.EventTypeDepositToPool: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewDepositToPoolEvent(event)
+		return &evt, err
+	},
+	liquiditytypes.EventTypeWithdrawFromPool: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewWithdrawFromPoolEvent(event)
+		return &evt, err
+	},
+	liquiditytypes.EventTypeSwapTransacted: func(event abcitypes.Event) (LiquidityEvent, error) {
+		evt, err := NewSwapTransactedEvent(event)
+		return &evt, err
+	},
+}
+
+// EndBlockEventTypes are the types of events emitted by the liquidity module
+// at the end of each block.
+var EndBlockEventTypes = []string{
+	liquiditytypes.EventTypeDepositToPool,
+	liquiditytypes.EventTypeWithdrawFromPool,
+	liquiditytypes.EventTypeSwapTransacted,
+}
+
+// SwapEventTypes are the types of end block events emitted for swaps.
+var SwapEventTypes = []string{
+	liquiditytypes.EventTypeSwapTransacted,
+}
+
+// DecodeEvent decodes event emitted at height using the registered decoder
+// for its type. ok is false if no decoder is registered for the type.
+func DecodeEvent(height int64, event abcitypes.Event) (evt LiquidityEvent, ok bool, err error) {
+	decode, ok := EventDecoders[event.Type]
+	if !ok {
+		return nil, false, nil
+	}
+	evt, err = decode(event)
+	if err != nil {
+		return nil, true, fmt.Errorf("decode %s event: %w", event.Type, err)
+	}
+	evt.setHeight(height)
+	return evt, true, nil
+}
+
+// DecodeEvents decodes all events emitted at height that have a registered
+// decoder, skipping the others.
+func DecodeEvents(height int64, events []abcitypes.Event) ([]LiquidityEvent, error) {
+	var evts []LiquidityEvent
+	for _, event := range events {
+		evt, ok, err := DecodeEvent(height, event)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			evts = append(evts, evt)
+		}
+	}
+	return evts, nil
+}
+
+// EventAddress returns the address that requested the batched operation
+// executed by an end block event. ok is false for other events.
+func EventAddress(evt LiquidityEvent) (addr string, ok bool) {
+	switch evt := evt.(type) {
+	case *SwapTransactedEvent:
+		return evt.SwapRequesterAddress, true
+	case *DepositToPoolEvent:
+		return evt.DepositorAddress, true
+	case *WithdrawFromPoolEvent:
+		return evt.WithdrawerAddress, true
+	default:
+		return "", false
+	}
+}
+
 type Block struct {
 	Height int64         `json:"height"`
 	Events []interface{} `json:"events"`
 }
 
 type Event struct {
+	Height     int64             `json:"height,omitempty"`
 	Type       string            `json:"type"`
 	Attributes map[string]string `json:"attributes"`
 }
@@ -30,6 +135,22 @@ func NewEvent(event abcitypes.Event) Event {
 	return evt
 }
 
+func (event Event) GetHeight() int64 {
+	return event.Height
+}
+
+func (event Event) GetType() string {
+	return event.Type
+}
+
+func (event Event) GetAttributes() map[string]string {
+	return event.Attributes
+}
+
+func (event *Event) setHeight(height int64) {
+	event.Height = height
+}
+
 func (event *Event) Attr(key string) (string, error) {
 	v, ok := event.Attributes[key]
 	if !ok {
@@ -98,15 +219,45 @@ func (event *Event) DecCoinAttrs(denomKey, amountKey string) (sdk.DecCoin, error
 	return sdk.NewDecCoinFromDec(denom, amount), nil
 }
 
+func (event *Event) BoolAttr(key string) (bool, error) {
+	s, err := event.Attr(key)
+	if err != nil {
+		return false, err
+	}
+	return s == liquiditytypes.Success, nil
+}
+
+func (event *Event) CoinsAttr(key string) (sdk.Coins, error) {
+	s, err := event.Attr(key)
+	if err != nil {
+		return nil, err
+	}
+	coins, err := sdk.ParseCoinsNormalized(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse coins: %w", err)
+	}
+	return coins, nil
+}
+
 type SwapTransactedEvent struct {
 	Event
-	Success                bool     `json:"success"`
-	PoolID                 uint64   `json:"pool_id"`
-	SwapRequesterAddress   string   `json:"swap_requester_address"`
-	ExchangedOfferCoin     sdk.Coin `json:"exchanged_offer_coin"`
+	Success              bool     `json:"success"`
+	PoolID               uint64   `json:"pool_id"`
+	SwapRequesterAddress string   `json:"swap_requester_address"`
+	ExchangedOfferCoin   sdk.Coin `json:"exchanged_offer_coin"`
+	// TransactedCoin is the amount of the offer coin exchanged in this
+	// batch, while ExchangedOfferCoin accumulates over the order's batches.
+	TransactedCoin         sdk.Coin `json:"transacted_coin"`
 	ExchangedOfferCoinFee  sdk.Coin `json:"exchanged_offer_coin_fee"`
 	ExchangedDemandCoin    sdk.Coin `json:"exchanged_demand_coin"`
 	ExchangedDemandCoinFee sdk.Coin `json:"exchanged_demand_coin_fee"`
+	// SwapPrice is the price of the batch, in X per Y of the pool's reserve
+	// coin denoms.
+	SwapPrice sdk.Dec `json:"swap_price"`
+}
+
+func (evt SwapTransactedEvent) GetPoolID() uint64 {
+	return evt.PoolID
 }
 
 func NewSwapTransactedEvent(event abcitypes.Event) (SwapTransactedEvent, error) {
@@ -129,6 +280,10 @@ func NewSwapTransactedEvent(event abcitypes.Event) (SwapTransactedEvent, error)
 		return SwapTransactedEvent{}, err
 	}
 	if evt.Success {
+		evt.TransactedCoin, err = evt.CoinAttrs(liquiditytypes.AttributeValueOfferCoinDenom, liquiditytypes.AttributeValueTransactedCoinAmount)
+		if err != nil {
+			return SwapTransactedEvent{}, err
+		}
 		evt.ExchangedDemandCoin, err = evt.CoinAttrs(liquiditytypes.AttributeValueDemandCoinDenom, liquiditytypes.AttributeValueExchangedDemandCoinAmount)
 		if err != nil {
 			return SwapTransactedEvent{}, err
@@ -142,6 +297,269 @@ func NewSwapTransactedEvent(event abcitypes.Event) (SwapTransactedEvent, error)
 			return SwapTransactedEvent{}, err
 		}
 		evt.ExchangedDemandCoinFee = sdk.NewCoin(feeDec.Denom, feeDec.Amount.Ceil().TruncateInt())
+		evt.SwapPrice, err = evt.DecAttr(liquiditytypes.AttributeValueSwapPrice)
+		if err != nil {
+			return SwapTransactedEvent{}, err
+		}
+	}
+	return evt, nil
+}
+
+// CreatePoolEvent is emitted when a pool is created by a transaction.
+type CreatePoolEvent struct {
+	Event
+	PoolID                uint64    `json:"pool_id"`
+	PoolTypeID            uint64    `json:"pool_type_id"`
+	PoolName              string    `json:"pool_name"`
+	ReserveAccountAddress string    `json:"reserve_account"`
+	DepositCoins          sdk.Coins `json:"deposit_coins"`
+	PoolCoinDenom         string    `json:"pool_coin_denom"`
+}
+
+func (evt CreatePoolEvent) GetPoolID() uint64 {
+	return evt.PoolID
+}
+
+func NewCreatePoolEvent(event abcitypes.Event) (CreatePoolEvent, error) {
+	evt := CreatePoolEvent{Event: NewEvent(event)}
+	var err error
+	evt.PoolID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolId)
+	if err != nil {
+		return CreatePoolEvent{}, err
+	}
+	evt.PoolTypeID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolTypeId)
+	if err != nil {
+		return CreatePoolEvent{}, err
+	}
+	evt.PoolName, err = evt.Attr(liquiditytypes.AttributeValuePoolName)
+	if err != nil {
+		return CreatePoolEvent{}, err
+	}
+	evt.ReserveAccountAddress, err = evt.Attr(liquiditytypes.AttributeValueReserveAccount)
+	if err != nil {
+		return CreatePoolEvent{}, err
+	}
+	evt.DepositCoins, err = evt.CoinsAttr(liquiditytypes.AttributeValueDepositCoins)
+	if err != nil {
+		return CreatePoolEvent{}, err
+	}
+	evt.PoolCoinDenom, err = evt.Attr(liquiditytypes.AttributeValuePoolCoinDenom)
+	if err != nil {
+		return CreatePoolEvent{}, err
+	}
+	return evt, nil
+}
+
+// DepositWithinBatchEvent is emitted when a deposit request is added to a batch.
+type DepositWithinBatchEvent struct {
+	Event
+	PoolID       uint64    `json:"pool_id"`
+	BatchIndex   uint64    `json:"batch_index"`
+	MsgIndex     uint64    `json:"msg_index"`
+	DepositCoins sdk.Coins `json:"deposit_coins"`
+}
+
+func (evt DepositWithinBatchEvent) GetPoolID() uint64 {
+	return evt.PoolID
+}
+
+func NewDepositWithinBatchEvent(event abcitypes.Event) (DepositWithinBatchEvent, error) {
+	evt := DepositWithinBatchEvent{Event: NewEvent(event)}
+	var err error
+	evt.PoolID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolId)
+	if err != nil {
+		return DepositWithinBatchEvent{}, err
+	}
+	evt.BatchIndex, err = evt.Uint64Attr(liquiditytypes.AttributeValueBatchIndex)
+	if err != nil {
+		return DepositWithinBatchEvent{}, err
+	}
+	evt.MsgIndex, err = evt.Uint64Attr(liquiditytypes.AttributeValueMsgIndex)
+	if err != nil {
+		return DepositWithinBatchEvent{}, err
+	}
+	evt.DepositCoins, err = evt.CoinsAttr(liquiditytypes.AttributeValueDepositCoins)
+	if err != nil {
+		return DepositWithinBatchEvent{}, err
+	}
+	return evt, nil
+}
+
+// WithdrawWithinBatchEvent is emitted when a withdrawal request is added to a batch.
+type WithdrawWithinBatchEvent struct {
+	Event
+	PoolID     uint64   `json:"pool_id"`
+	BatchIndex uint64   `json:"batch_index"`
+	MsgIndex   uint64   `json:"msg_index"`
+	PoolCoin   sdk.Coin `json:"pool_coin"`
+}
+
+func (evt WithdrawWithinBatchEvent) GetPoolID() uint64 {
+	return evt.PoolID
+}
+
+func NewWithdrawWithinBatchEvent(event abcitypes.Event) (WithdrawWithinBatchEvent, error) {
+	evt := WithdrawWithinBatchEvent{Event: NewEvent(event)}
+	var err error
+	evt.PoolID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolId)
+	if err != nil {
+		return WithdrawWithinBatchEvent{}, err
+	}
+	evt.BatchIndex, err = evt.Uint64Attr(liquiditytypes.AttributeValueBatchIndex)
+	if err != nil {
+		return WithdrawWithinBatchEvent{}, err
+	}
+	evt.MsgIndex, err = evt.Uint64Attr(liquiditytypes.AttributeValueMsgIndex)
+	if err != nil {
+		return WithdrawWithinBatchEvent{}, err
+	}
+	evt.PoolCoin, err = evt.CoinAttrs(liquiditytypes.AttributeValuePoolCoinDenom, liquiditytypes.AttributeValuePoolCoinAmount)
+	if err != nil {
+		return WithdrawWithinBatchEvent{}, err
+	}
+	return evt, nil
+}
+
+// SwapWithinBatchEvent is emitted when a swap request is added to a batch.
+type SwapWithinBatchEvent struct {
+	Event
+	PoolID          uint64   `json:"pool_id"`
+	BatchIndex      uint64   `json:"batch_index"`
+	MsgIndex        uint64   `json:"msg_index"`
+	OfferCoin       sdk.Coin `json:"offer_coin"`
+	OfferCoinFee    sdk.Coin `json:"offer_coin_fee"`
+	DemandCoinDenom string   `json:"demand_coin_denom"`
+	OrderPrice      sdk.Dec  `json:"order_price"`
+}
+
+func (evt SwapWithinBatchEvent) GetPoolID() uint64 {
+	return evt.PoolID
+}
+
+func NewSwapWithinBatchEvent(event abcitypes.Event) (SwapWithinBatchEvent, error) {
+	evt := SwapWithinBatchEvent{Event: NewEvent(event)}
+	var err error
+	evt.PoolID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolId)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	evt.BatchIndex, err = evt.Uint64Attr(liquiditytypes.AttributeValueBatchIndex)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	evt.MsgIndex, err = evt.Uint64Attr(liquiditytypes.AttributeValueMsgIndex)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	evt.OfferCoin, err = evt.CoinAttrs(liquiditytypes.AttributeValueOfferCoinDenom, liquiditytypes.AttributeValueOfferCoinAmount)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	evt.OfferCoinFee, err = evt.CoinAttrs(liquiditytypes.AttributeValueOfferCoinDenom, liquiditytypes.AttributeValueOfferCoinFeeAmount)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	evt.DemandCoinDenom, err = evt.Attr(liquiditytypes.AttributeValueDemandCoinDenom)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	evt.OrderPrice, err = evt.DecAttr(liquiditytypes.AttributeValueOrderPrice)
+	if err != nil {
+		return SwapWithinBatchEvent{}, err
+	}
+	return evt, nil
+}
+
+// DepositToPoolEvent is emitted at the end block when a batched deposit is executed.
+type DepositToPoolEvent struct {
+	Event
+	Success          bool      `json:"success"`
+	PoolID           uint64    `json:"pool_id"`
+	DepositorAddress string    `json:"depositor"`
+	AcceptedCoins    sdk.Coins `json:"accepted_coins"`
+	RefundedCoins    sdk.Coins `json:"refunded_coins"`
+	PoolCoin         sdk.Coin  `json:"pool_coin"`
+}
+
+func (evt DepositToPoolEvent) GetPoolID() uint64 {
+	return evt.PoolID
+}
+
+func NewDepositToPoolEvent(event abcitypes.Event) (DepositToPoolEvent, error) {
+	evt := DepositToPoolEvent{Event: NewEvent(event)}
+	var err error
+	evt.Success, err = evt.BoolAttr(liquiditytypes.AttributeValueSuccess)
+	if err != nil {
+		return DepositToPoolEvent{}, err
+	}
+	evt.PoolID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolId)
+	if err != nil {
+		return DepositToPoolEvent{}, err
+	}
+	evt.DepositorAddress, err = evt.Attr(liquiditytypes.AttributeValueDepositor)
+	if err != nil {
+		return DepositToPoolEvent{}, err
+	}
+	evt.AcceptedCoins, err = evt.CoinsAttr(liquiditytypes.AttributeValueAcceptedCoins)
+	if err != nil {
+		return DepositToPoolEvent{}, err
+	}
+	evt.RefundedCoins, err = evt.CoinsAttr(liquiditytypes.AttributeValueRefundedCoins)
+	if err != nil {
+		return DepositToPoolEvent{}, err
+	}
+	if evt.Success {
+		evt.PoolCoin, err = evt.CoinAttrs(liquiditytypes.AttributeValuePoolCoinDenom, liquiditytypes.AttributeValuePoolCoinAmount)
+		if err != nil {
+			return DepositToPoolEvent{}, err
+		}
+	}
+	return evt, nil
+}
+
+// WithdrawFromPoolEvent is emitted at the end block when a batched withdrawal is executed.
+type WithdrawFromPoolEvent struct {
+	Event
+	Success           bool      `json:"success"`
+	PoolID            uint64    `json:"pool_id"`
+	WithdrawerAddress string    `json:"withdrawer"`
+	PoolCoin          sdk.Coin  `json:"pool_coin"`
+	WithdrawCoins     sdk.Coins `json:"withdraw_coins"`
+	WithdrawFeeCoins  sdk.Coins `json:"withdraw_fee_coins"`
+}
+
+func (evt WithdrawFromPoolEvent) GetPoolID() uint64 {
+	return evt.PoolID
+}
+
+func NewWithdrawFromPoolEvent(event abcitypes.Event) (WithdrawFromPoolEvent, error) {
+	evt := WithdrawFromPoolEvent{Event: NewEvent(event)}
+	var err error
+	evt.Success, err = evt.BoolAttr(liquiditytypes.AttributeValueSuccess)
+	if err != nil {
+		return WithdrawFromPoolEvent{}, err
+	}
+	evt.PoolID, err = evt.Uint64Attr(liquiditytypes.AttributeValuePoolId)
+	if err != nil {
+		return WithdrawFromPoolEvent{}, err
+	}
+	evt.WithdrawerAddress, err = evt.Attr(liquiditytypes.AttributeValueWithdrawer)
+	if err != nil {
+		return WithdrawFromPoolEvent{}, err
+	}
+	evt.PoolCoin, err = evt.CoinAttrs(liquiditytypes.AttributeValuePoolCoinDenom, liquiditytypes.AttributeValuePoolCoinAmount)
+	if err != nil {
+		return WithdrawFromPoolEvent{}, err
+	}
+	if evt.Success {
+		evt.WithdrawCoins, err = evt.CoinsAttr(liquiditytypes.AttributeValueWithdrawCoins)
+		if err != nil {
+			return WithdrawFromPoolEvent{}, err
+		}
+		evt.WithdrawFeeCoins, err = evt.CoinsAttr(liquiditytypes.AttributeValueWithdrawFeeCoins)
+		if err != nil {
+			return WithdrawFromPoolEvent{}, err
+		}
 	}
 	return evt, nil
 }
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+const metricsNamespace = "gravity_dex"
+
+// Exporter polls the node and exposes pool reserves and swap statistics as
+// Prometheus metrics. Swap statistics accumulate from the first height
+// processed.
+type Exporter struct {
+	client      *Client
+	concurrency int
+	maxBlocks   int64
+
+	lastHeight   int64
+	traders      map[string]struct{}
+	lastReserves map[[2]string]float64 // (pool id, denom) => (amount) of the last poll
+
+	reserves     *prometheus.GaugeVec
+	swapVolume   *prometheus.CounterVec
+	swapFees     *prometheus.CounterVec
+	swaps        *prometheus.CounterVec
+	uniqueTrader prometheus.Gauge
+	latestHeight prometheus.Gauge
+	rpcErrors    *prometheus.CounterVec
+	decodeErrors prometheus.Counter
+}
+
+// NewExporter returns an exporter processing events from beginHeight, or
+// from the latest height at the first poll if beginHeight is zero. At most
+// maxBlocks blocks are processed per poll.
+func NewExporter(c *Client, beginHeight int64, concurrency int, maxBlocks int64) *Exporter {
+	e := &Exporter{
+		client:      c,
+		concurrency: concurrency,
+		maxBlocks:   maxBlocks,
+		traders:     make(map[string]struct{}),
+		reserves: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "pool_reserve",
+			Help:      "Reserve amount of a pool by denom.",
+		}, []string{"pool_id", "denom"}),
+		swapVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "swap_volume_total",
+			Help:      "Offer coin amount swapped in a pool by denom.",
+		}, []string{"pool_id", "denom"}),
+		swapFees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "swap_fees_total",
+			Help:      "Swap fees paid to a pool by denom.",
+		}, []string{"pool_id", "denom"}),
+		swaps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "swaps_total",
+			Help:      "Number of successful swaps in a pool.",
+		}, []string{"pool_id"}),
+		uniqueTrader: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "unique_traders",
+			Help:      "Number of distinct swap requesters.",
+		}),
+		latestHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "latest_processed_height",
+			Help:      "Latest block height processed.",
+		}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_errors_total",
+			Help:      "Number of failed node requests by method.",
+		}, []string{"method"}),
+		decodeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "decode_errors_total",
+			Help:      "Number of blocks skipped because their events could not be decoded.",
+		}),
+	}
+	if beginHeight > 0 {
+		e.lastHeight = beginHeight - 1
+	}
+	return e
+}
+
+// Collectors returns the metrics of e to be registered.
+func (e *Exporter) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		e.reserves, e.swapVolume, e.swapFees, e.swaps, e.uniqueTrader, e.latestHeight, e.rpcErrors, e.decodeErrors,
+	}
+}
+
+// Poll processes the events of new blocks and updates the reserves of all
+// pools at the latest height. Events of a failed poll are processed again by
+// the next one, while reserves are updated regardless.
+func (e *Exporter) Poll(ctx context.Context) error {
+	latest, err := e.client.LatestBlockHeight(ctx)
+	if err != nil {
+		e.rpcErrors.WithLabelValues("latest_block_height").Inc()
+		return fmt.Errorf("get latest block height: %w", err)
+	}
+	if e.lastHeight == 0 {
+		e.lastHeight = latest - 1
+	}
+	eventsErr := e.pollEvents(ctx, latest)
+	reservesErr := e.updateReserves(ctx, latest)
+	if eventsErr != nil {
+		return eventsErr
+	}
+	return reservesErr
+}
+
+func (e *Exporter) pollEvents(ctx context.Context, latest int64) error {
+	endHeight := latest
+	if endHeight-e.lastHeight > e.maxBlocks {
+		endHeight = e.lastHeight + e.maxBlocks
+	}
+	if endHeight <= e.lastHeight {
+		return nil
+	}
+	heights, err := e.client.SearchEventBlockHeights(ctx, EndBlockEventTypes, e.lastHeight+1, endHeight)
+	if err != nil {
+		e.rpcErrors.WithLabelValues("block_search").Inc()
+		return fmt.Errorf("search block heights: %w", err)
+	}
+	// Events are only counted once all blocks are fetched, so that a
+	// failed poll doesn't count them twice.
+	var evts []LiquidityEvent
+	var numUndecodable int
+	if err := e.client.IterateEndBlockEvents(ctx, heights, e.concurrency, func(height int64, events []abcitypes.Event) error {
+		blockEvts, err := DecodeEvents(height, events)
+		if err != nil {
+			// A block that can't be decoded is skipped, since retrying it
+			// would stall the exporter forever.
+			numUndecodable++
+			return nil
+		}
+		evts = append(evts, blockEvts...)
+		return nil
+	}); err != nil {
+		e.rpcErrors.WithLabelValues("block_results").Inc()
+		return fmt.Errorf("iterate end block events: %w", err)
+	}
+	for _, evt := range evts {
+		e.addEvent(evt)
+	}
+	e.decodeErrors.Add(float64(numUndecodable))
+	e.lastHeight = endHeight
+	e.latestHeight.Set(float64(endHeight))
+	return nil
+}
+
+func (e *Exporter) updateReserves(ctx context.Context, height int64) error {
+	pools, err := e.client.Pools(ctx, WithBlockHeight(height))
+	if err != nil {
+		e.rpcErrors.WithLabelValues("pools").Inc()
+		return fmt.Errorf("get pools: %w", err)
+	}
+	reserves := make(map[[2]string]float64) // (pool id, denom) => (amount)
+	for _, pool := range pools {
+		for _, denom := range pool.ReserveCoinDenoms {
+			balance, err := e.client.Balance(ctx, pool.ReserveAccountAddress, denom, WithBlockHeight(height))
+			if err != nil {
+				e.rpcErrors.WithLabelValues("balance").Inc()
+				return fmt.Errorf("get balance: %w", err)
+			}
+			reserves[[2]string{strconv.FormatUint(pool.Id, 10), denom}] = intFloat64(balance.Amount)
+		}
+	}
+	for labels := range e.lastReserves {
+		if _, ok := reserves[labels]; !ok {
+			e.reserves.DeleteLabelValues(labels[0], labels[1])
+		}
+	}
+	for labels, amount := range reserves {
+		e.reserves.WithLabelValues(labels[0], labels[1]).Set(amount)
+	}
+	e.lastReserves = reserves
+	return nil
+}
+
+func (e *Exporter) addEvent(evt LiquidityEvent) {
+	ste, ok := evt.(*SwapTransactedEvent)
+	if !ok || !ste.Success {
+		return
+	}
+	poolID := strconv.FormatUint(ste.PoolID, 10)
+	e.swaps.WithLabelValues(poolID).Inc()
+	e.swapVolume.WithLabelValues(poolID, ste.TransactedCoin.Denom).Add(intFloat64(ste.TransactedCoin.Amount))
+	e.swapFees.WithLabelValues(poolID, ste.ExchangedOfferCoinFee.Denom).Add(intFloat64(ste.ExchangedOfferCoinFee.Amount))
+	e.swapFees.WithLabelValues(poolID, ste.ExchangedDemandCoinFee.Denom).Add(intFloat64(ste.ExchangedDemandCoinFee.Amount))
+	e.traders[ste.SwapRequesterAddress] = struct{}{}
+	e.uniqueTrader.Set(float64(len(e.traders)))
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+// Genesis holds the parts of a genesis file used for statistics.
+type Genesis struct {
+	ChainID       string
+	GenesisTime   time.Time
+	InitialHeight int64
+	Bank          *banktypes.GenesisState
+	// Liquidity is nil if the genesis has no liquidity module state.
+	Liquidity *liquiditytypes.GenesisState
+}
+
+func ReadGenesis(path string) (*Genesis, error) {
+	appState, genDoc, err := genutiltypes.GenesisStateFromGenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("genesis state from file: %w", err)
+	}
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	gen := &Genesis{
+		ChainID:       genDoc.ChainID,
+		GenesisTime:   genDoc.GenesisTime,
+		InitialHeight: genDoc.InitialHeight,
+		Bank:          banktypes.GetGenesisStateFromAppState(cdc, appState),
+	}
+	if bz, ok := appState[liquiditytypes.ModuleName]; ok {
+		var genState liquiditytypes.GenesisState
+		if err := cdc.UnmarshalJSON(bz, &genState); err != nil {
+			return nil, fmt.Errorf("unmarshal liquidity genesis state: %w", err)
+		}
+		gen.Liquidity = &genState
+	}
+	return gen, nil
+}
+
+// Pools returns the pools of the liquidity module state of gen.
+func (gen *Genesis) Pools() ([]liquiditytypes.Pool, error) {
+	if gen.Liquidity == nil {
+		return nil, fmt.Errorf("genesis has no liquidity module state")
+	}
+	pools := make([]liquiditytypes.Pool, 0, len(gen.Liquidity.PoolRecords))
+	for _, record := range gen.Liquidity.PoolRecords {
+		pools = append(pools, record.Pool)
+	}
+	return pools, nil
+}
+
+// InvestorSnapshot returns the pool coin holdings of the genesis balances.
+func (gen *Genesis) InvestorSnapshot(pools []liquiditytypes.Pool) *InvestorSnapshot {
+	snapshot := NewInvestorSnapshot(pools)
+	for _, balance := range gen.Bank.Balances {
+		for _, coin := range balance.Coins {
+			snapshot.AddBalance(balance.Address, coin)
+		}
+	}
+	return snapshot
+}
+
+// GenesisPool describes a pool in a genesis. PoolCoinSupply is the supply
+// recorded by the liquidity module, which should equal both BankSupply and
+// PoolCoinHeld, the sum of all balances of the pool coin.
+type GenesisPool struct {
+	ID                uint64    `json:"id"`
+	PoolCoinDenom     string    `json:"pool_coin_denom"`
+	ReserveCoins      sdk.Coins `json:"reserve_coins"`
+	PoolCoinSupply    sdk.Int   `json:"pool_coin_supply"`
+	BankSupply        sdk.Int   `json:"bank_supply"`
+	PoolCoinHeld      sdk.Int   `json:"pool_coin_held"`
+	SupplyMatches     bool      `json:"supply_matches"`
+	BatchIndex        uint64    `json:"batch_index"`
+	BatchExecuted     bool      `json:"batch_executed"`
+	NumDepositMsgs    int       `json:"num_deposit_msgs"`
+	NumWithdrawalMsgs int       `json:"num_withdrawal_msgs"`
+	NumSwapMsgs       int       `json:"num_swap_msgs"`
+}
+
+type GenesisReport struct {
+	ChainID       string                 `json:"chain_id"`
+	GenesisTime   time.Time              `json:"genesis_time"`
+	InitialHeight int64                  `json:"initial_height"`
+	Params        *liquiditytypes.Params `json:"params,omitempty"`
+	Pools         []GenesisPool          `json:"pools"`
+	Investors     InvestorReport         `json:"investors"`
+}
+
+// NewGenesisReport analyzes gen for pools. Pools missing from the liquidity
+// state of gen are reported with zero reserves and supply.
+func NewGenesisReport(gen *Genesis, pools []liquiditytypes.Pool, withInvestors bool) GenesisReport {
+	snapshot := gen.InvestorSnapshot(pools)
+	report := GenesisReport{
+		ChainID:       gen.ChainID,
+		GenesisTime:   gen.GenesisTime,
+		InitialHeight: gen.InitialHeight,
+		Investors: InvestorReport{
+			NumInvestors: snapshot.NumInvestors(),
+			Pools:        snapshot.Stats(withInvestors),
+		},
+	}
+	records := make(map[uint64]liquiditytypes.PoolRecord)
+	if gen.Liquidity != nil {
+		report.Params = &gen.Liquidity.Params
+		for _, record := range gen.Liquidity.PoolRecords {
+			records[record.Pool.Id] = record
+		}
+	}
+	for i, pool := range pools {
+		gp := GenesisPool{
+			ID:             pool.Id,
+			PoolCoinDenom:  pool.PoolCoinDenom,
+			ReserveCoins:   sdk.NewCoins(),
+			PoolCoinSupply: sdk.ZeroInt(),
+			BankSupply:     gen.Bank.Supply.AmountOf(pool.PoolCoinDenom),
+			PoolCoinHeld:   report.Investors.Pools[i].PoolCoinHeld,
+		}
+		if record, ok := records[pool.Id]; ok {
+			gp.ReserveCoins = record.PoolMetadata.ReserveCoins
+			gp.PoolCoinSupply = record.PoolMetadata.PoolCoinTotalSupply.Amount
+			gp.BatchIndex = record.PoolBatch.Index
+			gp.BatchExecuted = record.PoolBatch.Executed
+			gp.NumDepositMsgs = len(record.DepositMsgStates)
+			gp.NumWithdrawalMsgs = len(record.WithdrawMsgStates)
+			gp.NumSwapMsgs = len(record.SwapMsgStates)
+		}
+		gp.SupplyMatches = gp.PoolCoinSupply.Equal(gp.BankSupply) && gp.BankSupply.Equal(gp.PoolCoinHeld)
+		report.Pools = append(report.Pools, gp)
+	}
+	return report
+}
+
+// WriteGenesisReport writes report to w in the given format.
+func WriteGenesisReport(w io.Writer, format string, report GenesisReport) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatTable:
+		return writeGenesisTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeGenesisTable(w io.Writer, report GenesisReport) error {
+	fmt.Fprintln(w, "number of pool investors")
+	fmt.Fprintln(w, "========================")
+	for _, st := range report.Investors.Pools {
+		fmt.Fprintf(w, "pool %d: %d\n", st.PoolID, st.NumInvestors)
+	}
+	fmt.Fprintf(w, "total: %d\n", report.Investors.NumInvestors)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "genesis of %s at %s (initial height %d)\n",
+		report.ChainID, report.GenesisTime.Format(time.RFC3339), report.InitialHeight)
+	if report.Params != nil {
+		fmt.Fprintf(w, "* swap fee rate: %s\n", report.Params.SwapFeeRate)
+		fmt.Fprintf(w, "* withdraw fee rate: %s\n", report.Params.WithdrawFeeRate)
+		fmt.Fprintf(w, "* max order amount ratio: %s\n", report.Params.MaxOrderAmountRatio)
+		fmt.Fprintf(w, "* unit batch height: %d\n", report.Params.UnitBatchHeight)
+		fmt.Fprintf(w, "* circuit breaker enabled: %t\n", report.Params.CircuitBreakerEnabled)
+	} else {
+		fmt.Fprintln(w, "* no liquidity module state")
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "pools")
+	fmt.Fprintln(w, "=====")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ID\tRESERVES\tPOOL COIN SUPPLY\tBANK SUPPLY\tHELD\tSUPPLY OK\tBATCH\tDEPOSITS\tWITHDRAWALS\tSWAPS\t")
+	for _, gp := range report.Pools {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%t\t%d\t%d\t%d\t%d\t\n",
+			gp.ID, gp.ReserveCoins, gp.PoolCoinSupply, gp.BankSupply, gp.PoolCoinHeld, gp.SupplyMatches,
+			gp.BatchIndex, gp.NumDepositMsgs, gp.NumWithdrawalMsgs, gp.NumSwapMsgs)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "LP concentration")
+	fmt.Fprintln(w, "================")
+	if err := writeInvestorTable(w, report.Investors); err != nil {
+		return err
+	}
+
+	for _, st := range report.Investors.Pools {
+		if len(st.Investors) == 0 {
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "investors of pool %d\n", st.PoolID)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "ADDRESS\tAMOUNT\tSHARE\t")
+		for _, investor := range st.Investors {
+			fmt.Fprintf(tw, "%s\t%s\t%s%%\t\n", investor.Address, investor.Amount, formatPercent(investor.Share))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
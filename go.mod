@@ -6,7 +6,10 @@ replace github.com/gogo/protobuf => github.com/regen-network/protobuf v1.3.3-alp
 
 require (
 	github.com/cosmos/cosmos-sdk v0.42.6
+	github.com/gorilla/websocket v1.4.2
 	github.com/gravity-devs/liquidity v1.2.9
+	github.com/mattn/go-sqlite3 v1.14.8
+	github.com/prometheus/client_golang v1.10.0
 	github.com/schollz/progressbar/v3 v3.8.2
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/viper v1.7.1
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+// InvestorSnapshot holds the pool coin balances of every investor of a set
+// of pools at some point.
+type InvestorSnapshot struct {
+	pools    []liquiditytypes.Pool
+	poolIDs  map[string]uint64             // (pool coin denom) => (pool id)
+	holdings map[uint64]map[string]sdk.Int // (pool id) => (address) => (pool coin amount)
+}
+
+func NewInvestorSnapshot(pools []liquiditytypes.Pool) *InvestorSnapshot {
+	s := &InvestorSnapshot{
+		pools:    pools,
+		poolIDs:  make(map[string]uint64),
+		holdings: make(map[uint64]map[string]sdk.Int),
+	}
+	for _, pool := range pools {
+		s.poolIDs[pool.PoolCoinDenom] = pool.Id
+		s.holdings[pool.Id] = make(map[string]sdk.Int)
+	}
+	return s
+}
+
+// AddBalance records coin as held by addr if it is a positive amount of a
+// pool coin.
+func (s *InvestorSnapshot) AddBalance(addr string, coin sdk.Coin) {
+	poolID, ok := s.poolIDs[coin.Denom]
+	if !ok || !coin.Amount.IsPositive() {
+		return
+	}
+	if amt, ok := s.holdings[poolID][addr]; ok {
+		s.holdings[poolID][addr] = amt.Add(coin.Amount)
+	} else {
+		s.holdings[poolID][addr] = coin.Amount
+	}
+}
+
+// Investor is the pool coin holding of an address, and its share of all
+// pool coins held.
+type Investor struct {
+	Address string  `json:"address"`
+	Amount  sdk.Int `json:"amount"`
+	Share   sdk.Dec `json:"share"`
+}
+
+// Investors returns the investors of the pool with poolID, largest first.
+func (s *InvestorSnapshot) Investors(poolID uint64) []Investor {
+	total := sdk.ZeroInt()
+	for _, amt := range s.holdings[poolID] {
+		total = total.Add(amt)
+	}
+	investors := make([]Investor, 0, len(s.holdings[poolID]))
+	for addr, amt := range s.holdings[poolID] {
+		investors = append(investors, Investor{
+			Address: addr,
+			Amount:  amt,
+			Share:   amt.ToDec().Quo(total.ToDec()),
+		})
+	}
+	sort.Slice(investors, func(i, j int) bool {
+		if !investors[i].Amount.Equal(investors[j].Amount) {
+			return investors[i].Amount.GT(investors[j].Amount)
+		}
+		return investors[i].Address < investors[j].Address
+	})
+	return investors
+}
+
+// NumInvestors returns the number of distinct addresses holding any pool coin.
+func (s *InvestorSnapshot) NumInvestors() int {
+	addrs := make(map[string]struct{})
+	for _, holdings := range s.holdings {
+		for addr := range holdings {
+			addrs[addr] = struct{}{}
+		}
+	}
+	return len(addrs)
+}
+
+// PoolInvestorStats describes how the pool coins of a pool are distributed
+// among its investors. Shares are of the pool coins held by investors.
+type PoolInvestorStats struct {
+	PoolID        uint64     `json:"pool_id"`
+	PoolCoinDenom string     `json:"pool_coin_denom"`
+	NumInvestors  int        `json:"num_investors"`
+	PoolCoinHeld  sdk.Int    `json:"pool_coin_held"`
+	TopShare      sdk.Dec    `json:"top_share"`
+	Top10Share    sdk.Dec    `json:"top10_share"`
+	MedianAmount  sdk.Int    `json:"median_amount"`
+	Gini          float64    `json:"gini"`
+	Investors     []Investor `json:"investors,omitempty"`
+}
+
+// Stats returns the stats of every pool, in the order of the pools given to
+// NewInvestorSnapshot. If withInvestors is true, the stats include the list
+// of investors.
+func (s *InvestorSnapshot) Stats(withInvestors bool) []PoolInvestorStats {
+	var stats []PoolInvestorStats
+	for _, pool := range s.pools {
+		investors := s.Investors(pool.Id)
+		st := PoolInvestorStats{
+			PoolID:        pool.Id,
+			PoolCoinDenom: pool.PoolCoinDenom,
+			NumInvestors:  len(investors),
+			PoolCoinHeld:  sdk.ZeroInt(),
+			TopShare:      sdk.ZeroDec(),
+			Top10Share:    sdk.ZeroDec(),
+			MedianAmount:  sdk.ZeroInt(),
+		}
+		for i, investor := range investors {
+			st.PoolCoinHeld = st.PoolCoinHeld.Add(investor.Amount)
+			if i < 10 {
+				st.Top10Share = st.Top10Share.Add(investor.Share)
+			}
+		}
+		if len(investors) > 0 {
+			st.TopShare = investors[0].Share
+			st.MedianAmount = investors[len(investors)/2].Amount
+		}
+		st.Gini = gini(investors)
+		if withInvestors {
+			st.Investors = investors
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+// gini returns the Gini coefficient of the amounts of investors, which must
+// be sorted largest first. It is 0 when every investor holds the same amount
+// and approaches 1 when a single investor holds everything.
+func gini(investors []Investor) float64 {
+	n := float64(len(investors))
+	var sum, weighted float64
+	for i, investor := range investors {
+		amt := intFloat64(investor.Amount)
+		rank := n - float64(i) // rank in ascending order, starting from 1
+		sum += amt
+		weighted += (2*rank - n - 1) * amt
+	}
+	if sum == 0 {
+		return 0
+	}
+	return weighted / (n * sum)
+}
+
+type InvestorReport struct {
+	Height       int64               `json:"height,omitempty"`
+	NumInvestors int                 `json:"num_investors"`
+	Pools        []PoolInvestorStats `json:"pools"`
+}
+
+// WriteInvestorReport writes report to w in the given format.
+func WriteInvestorReport(w io.Writer, format string, report InvestorReport) error {
+	switch format {
+	case FormatCSV:
+		return writeInvestorCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatTable:
+		return writeInvestorTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeInvestorCSV(w io.Writer, report InvestorReport) error {
+	records := [][]string{{
+		"id", "pool_coin_denom", "investors", "pool_coin_held", "top_share", "top10_share", "median_amount", "gini",
+	}}
+	for _, st := range report.Pools {
+		records = append(records, []string{
+			strconv.FormatUint(st.PoolID, 10),
+			st.PoolCoinDenom,
+			strconv.Itoa(st.NumInvestors),
+			st.PoolCoinHeld.String(),
+			st.TopShare.String(),
+			st.Top10Share.String(),
+			st.MedianAmount.String(),
+			strconv.FormatFloat(st.Gini, 'f', 4, 64),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeInvestorTable(w io.Writer, report InvestorReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ID\tINVESTORS\tPOOL COIN HELD\tTOP\tTOP 10\tMEDIAN\tGINI\t")
+	for _, st := range report.Pools {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s%%\t%s%%\t%s\t%.4f\t\n",
+			st.PoolID, st.NumInvestors, st.PoolCoinHeld,
+			formatPercent(st.TopShare), formatPercent(st.Top10Share), st.MedianAmount, st.Gini)
+	}
+	fmt.Fprintf(tw, "total\t%d\t\t\t\t\t\t\n", report.NumInvestors)
+	return tw.Flush()
+}
+
+// formatPercent formats share as a percentage with two decimals.
+func formatPercent(share sdk.Dec) string {
+	f, err := strconv.ParseFloat(share.String(), 64)
+	if err != nil {
+		return share.String()
+	}
+	return strconv.FormatFloat(f*100, 'f', 2, 64)
+}
+
+// InvestorChange is the change of an investor's pool coin holding between
+// two snapshots.
+type InvestorChange struct {
+	Address string  `json:"address"`
+	Old     sdk.Int `json:"old"`
+	New     sdk.Int `json:"new"`
+	Delta   sdk.Int `json:"delta"`
+}
+
+// PoolInvestorDiff lists the investors of a pool whose holdings changed
+// between two snapshots, each list sorted by descending absolute change.
+type PoolInvestorDiff struct {
+	PoolID        uint64           `json:"pool_id"`
+	PoolCoinDenom string           `json:"pool_coin_denom"`
+	OldInvestors  int              `json:"old_investors"`
+	NewInvestors  int              `json:"new_investors"`
+	Joined        []InvestorChange `json:"joined"`
+	Left          []InvestorChange `json:"left"`
+	Increased     []InvestorChange `json:"increased"`
+	Decreased     []InvestorChange `json:"decreased"`
+}
+
+// DiffInvestors compares the holdings of every pool present in either
+// snapshot, ordered by pool id.
+func DiffInvestors(from, to *InvestorSnapshot) []PoolInvestorDiff {
+	denoms := make(map[uint64]string)
+	for _, s := range []*InvestorSnapshot{from, to} {
+		for _, pool := range s.pools {
+			denoms[pool.Id] = pool.PoolCoinDenom
+		}
+	}
+	poolIDs := make([]uint64, 0, len(denoms))
+	for id := range denoms {
+		poolIDs = append(poolIDs, id)
+	}
+	sort.Slice(poolIDs, func(i, j int) bool { return poolIDs[i] < poolIDs[j] })
+
+	var diffs []PoolInvestorDiff
+	for _, id := range poolIDs {
+		oldHoldings, newHoldings := from.holdings[id], to.holdings[id]
+		diff := PoolInvestorDiff{
+			PoolID:        id,
+			PoolCoinDenom: denoms[id],
+			OldInvestors:  len(oldHoldings),
+			NewInvestors:  len(newHoldings),
+		}
+		addrs := make(map[string]struct{})
+		for addr := range oldHoldings {
+			addrs[addr] = struct{}{}
+		}
+		for addr := range newHoldings {
+			addrs[addr] = struct{}{}
+		}
+		for addr := range addrs {
+			oldAmt, ok := oldHoldings[addr]
+			if !ok {
+				oldAmt = sdk.ZeroInt()
+			}
+			newAmt, ok := newHoldings[addr]
+			if !ok {
+				newAmt = sdk.ZeroInt()
+			}
+			change := InvestorChange{Address: addr, Old: oldAmt, New: newAmt, Delta: newAmt.Sub(oldAmt)}
+			switch {
+			case oldAmt.IsZero():
+				diff.Joined = append(diff.Joined, change)
+			case newAmt.IsZero():
+				diff.Left = append(diff.Left, change)
+			case newAmt.GT(oldAmt):
+				diff.Increased = append(diff.Increased, change)
+			case newAmt.LT(oldAmt):
+				diff.Decreased = append(diff.Decreased, change)
+			}
+		}
+		for _, changes := range [][]InvestorChange{diff.Joined, diff.Left, diff.Increased, diff.Decreased} {
+			sortInvestorChanges(changes)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func sortInvestorChanges(changes []InvestorChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if c := changes[i].Delta.BigInt().CmpAbs(changes[j].Delta.BigInt()); c != 0 {
+			return c > 0
+		}
+		return changes[i].Address < changes[j].Address
+	})
+}
+
+type InvestorDiffReport struct {
+	From  string             `json:"from"`
+	To    string             `json:"to"`
+	Pools []PoolInvestorDiff `json:"pools"`
+}
+
+// WriteInvestorDiffReport writes report to w in the given format. The table
+// format only lists each investor if details is true.
+func WriteInvestorDiffReport(w io.Writer, format string, report InvestorDiffReport, details bool) error {
+	switch format {
+	case FormatCSV:
+		return writeInvestorDiffCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatTable:
+		return writeInvestorDiffTable(w, report, details)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeInvestorDiffCSV(w io.Writer, report InvestorDiffReport) error {
+	records := [][]string{{"id", "address", "change", "old", "new", "delta"}}
+	for _, diff := range report.Pools {
+		for _, group := range diff.groups() {
+			for _, change := range group.changes {
+				records = append(records, []string{
+					strconv.FormatUint(diff.PoolID, 10),
+					change.Address,
+					group.name,
+					change.Old.String(),
+					change.New.String(),
+					change.Delta.String(),
+				})
+			}
+		}
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeInvestorDiffTable(w io.Writer, report InvestorDiffReport, details bool) error {
+	fmt.Fprintf(w, "pool investors from %s to %s\n", report.From, report.To)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ID\tOLD\tNEW\tJOINED\tLEFT\tINCREASED\tDECREASED\t")
+	for _, diff := range report.Pools {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			diff.PoolID, diff.OldInvestors, diff.NewInvestors,
+			len(diff.Joined), len(diff.Left), len(diff.Increased), len(diff.Decreased))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !details {
+		return nil
+	}
+	for _, diff := range report.Pools {
+		for _, group := range diff.groups() {
+			if len(group.changes) == 0 {
+				continue
+			}
+			fmt.Fprintln(w)
+			fmt.Fprintf(w, "pool %d: %s\n", diff.PoolID, group.name)
+			tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(tw, "ADDRESS\tOLD\tNEW\tDELTA\t")
+			for _, change := range group.changes {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", change.Address, change.Old, change.New, change.Delta)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type investorChangeGroup struct {
+	name    string
+	changes []InvestorChange
+}
+
+func (diff PoolInvestorDiff) groups() []investorChangeGroup {
+	return []investorChangeGroup{
+		{"joined", diff.Joined},
+		{"left", diff.Left},
+		{"increased", diff.Increased},
+		{"decreased", diff.Decreased},
+	}
+}
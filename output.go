@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats supported by commands writing reports.
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatTable = "table"
+)
+
+// SummaryMetadata describes the range a summary was computed over.
+type SummaryMetadata struct {
+	BeginHeight int64     `json:"begin_height"`
+	EndHeight   int64     `json:"end_height"`
+	BeginTime   time.Time `json:"begin_time"`
+	EndTime     time.Time `json:"end_time"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// UnpricedDenoms lists the denoms that could not be priced, if prices
+	// were derived from pool reserves.
+	UnpricedDenoms []string `json:"unpriced_denoms,omitempty"`
+}
+
+type SummaryReport struct {
+	SummaryMetadata
+	Pools []*PoolSummary `json:"pools"`
+}
+
+// WriteSummaryReport writes report to w in the given format.
+func WriteSummaryReport(w io.Writer, format string, report SummaryReport) error {
+	switch format {
+	case FormatCSV:
+		return writeSummaryCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, ps := range report.Pools {
+			if err := enc.Encode(struct {
+				SummaryMetadata
+				*PoolSummary
+			}{report.SummaryMetadata, ps}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTable:
+		return writeSummaryTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeSummaryCSV(w io.Writer, report SummaryReport) error {
+	records := [][]string{{
+		"id", "x_denom", "y_denom", "x", "y",
+		"offer_x", "offer_x_fee", "demand_y", "demand_y_fee",
+		"offer_y", "offer_y_fee", "demand_x", "demand_x_fee",
+		"deposits", "deposit_x", "deposit_y", "pool_coin_minted",
+		"withdrawals", "withdraw_x", "withdraw_y", "withdraw_fee_x", "withdraw_fee_y", "pool_coin_burned",
+		"reserve_usd", "swap_volume_usd", "fee_usd",
+	}}
+	for _, ps := range report.Pools {
+		usd := make([]string, 3)
+		if ps.Value != nil {
+			usd[0] = formatUSD(ps.Value.Reserve)
+			usd[1] = formatUSD(ps.Value.SwapVolume)
+			usd[2] = formatUSD(ps.Value.Fees)
+		}
+		records = append(records, []string{
+			strconv.FormatUint(ps.ID, 10),
+			ps.ReserveCoins[0].Denom,
+			ps.ReserveCoins[1].Denom,
+			ps.ReserveCoins[0].Amount.String(),
+			ps.ReserveCoins[1].Amount.String(),
+			ps.Swaps[0].OfferCoin.Amount.String(),
+			ps.Swaps[0].OfferCoinFee.Amount.String(),
+			ps.Swaps[0].DemandCoin.Amount.String(),
+			ps.Swaps[0].DemandCoinFee.Amount.String(),
+			ps.Swaps[1].OfferCoin.Amount.String(),
+			ps.Swaps[1].OfferCoinFee.Amount.String(),
+			ps.Swaps[1].DemandCoin.Amount.String(),
+			ps.Swaps[1].DemandCoinFee.Amount.String(),
+			strconv.Itoa(ps.Deposits.Count),
+			ps.Deposits.Coins[0].Amount.String(),
+			ps.Deposits.Coins[1].Amount.String(),
+			ps.Deposits.PoolCoinMinted.Amount.String(),
+			strconv.Itoa(ps.Withdrawals.Count),
+			ps.Withdrawals.Coins[0].Amount.String(),
+			ps.Withdrawals.Coins[1].Amount.String(),
+			ps.Withdrawals.FeeCoins[0].Amount.String(),
+			ps.Withdrawals.FeeCoins[1].Amount.String(),
+			ps.Withdrawals.PoolCoinBurned.Amount.String(),
+			usd[0], usd[1], usd[2],
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeSummaryTable(w io.Writer, report SummaryReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ID\tRESERVE X\tRESERVE Y\tOFFER X\tOFFER Y\tFEE X\tFEE Y\tDEPOSITS\tWITHDRAWALS\tRESERVE USD\t")
+	for _, ps := range report.Pools {
+		var reserveUSD string
+		if ps.Value != nil {
+			reserveUSD = formatUSD(ps.Value.Reserve)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t\n",
+			ps.ID,
+			ps.ReserveCoins[0], ps.ReserveCoins[1],
+			ps.Swaps[0].OfferCoin, ps.Swaps[1].OfferCoin,
+			ps.Swaps[0].OfferCoinFee.Add(ps.Swaps[1].DemandCoinFee),
+			ps.Swaps[1].OfferCoinFee.Add(ps.Swaps[0].DemandCoinFee),
+			ps.Deposits.Count, ps.Withdrawals.Count, reserveUSD)
+	}
+	return tw.Flush()
+}
+
+func formatUSD(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// writeOutput calls write with the file named fileName, or with stdout if
+// fileName is "-".
+func writeOutput(fileName string, write func(w io.Writer) error) error {
+	if fileName == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+// PoolState is the state of a pool at a height. Price is the pool price in
+// X per Y, like swap prices, and is nil if the pool has no Y reserve.
+type PoolState struct {
+	ReserveCoins   [2]sdk.Coin `json:"reserve_coins"`
+	PoolCoinSupply sdk.Coin    `json:"pool_coin_supply"`
+	Price          *sdk.Dec    `json:"price"`
+}
+
+// LoadPoolStates loads the state of pools at height, keyed by pool id.
+func LoadPoolStates(ctx context.Context, c *Client, pools []liquiditytypes.Pool, height int64) (map[uint64]PoolState, error) {
+	summaries, err := LoadPoolReserves(ctx, c, pools, height)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[uint64]PoolState)
+	for i, pool := range pools {
+		supply, err := c.Supply(ctx, pool.PoolCoinDenom, WithBlockHeight(height))
+		if err != nil {
+			return nil, fmt.Errorf("get supply: %w", err)
+		}
+		state := PoolState{ReserveCoins: summaries[i].ReserveCoins, PoolCoinSupply: supply}
+		if x, y := state.ReserveCoins[0].Amount, state.ReserveCoins[1].Amount; y.IsPositive() {
+			price := x.ToDec().Quo(y.ToDec())
+			state.Price = &price
+		}
+		states[pool.Id] = state
+	}
+	return states, nil
+}
+
+// Pool diff statuses.
+const (
+	PoolStatusNew     = "new"
+	PoolStatusRemoved = "removed"
+	PoolStatusChanged = "changed"
+)
+
+// PoolDiff is the change of a pool between two heights. Old is nil for new
+// pools and New is nil for removed pools, in which case changes are
+// relative to an empty pool. PriceChange is the relative change of the
+// price, and is nil unless both prices are known.
+type PoolDiff struct {
+	ID                   uint64     `json:"id"`
+	PoolCoinDenom        string     `json:"pool_coin_denom"`
+	Status               string     `json:"status"`
+	Old                  *PoolState `json:"old"`
+	New                  *PoolState `json:"new"`
+	ReserveChanges       [2]sdk.Int `json:"reserve_changes"`
+	PoolCoinSupplyChange sdk.Int    `json:"pool_coin_supply_change"`
+	PriceChange          *sdk.Dec   `json:"price_change"`
+}
+
+// DiffPools compares the pools and their states at two heights, ordered by
+// pool id.
+func DiffPools(oldPools []liquiditytypes.Pool, oldStates map[uint64]PoolState, newPools []liquiditytypes.Pool, newStates map[uint64]PoolState) []PoolDiff {
+	pools := make(map[uint64]liquiditytypes.Pool)
+	for _, pool := range oldPools {
+		pools[pool.Id] = pool
+	}
+	for _, pool := range newPools {
+		pools[pool.Id] = pool
+	}
+	var diffs []PoolDiff
+	for id, pool := range pools {
+		diff := PoolDiff{ID: id, PoolCoinDenom: pool.PoolCoinDenom, Status: PoolStatusChanged}
+		empty := PoolState{PoolCoinSupply: sdk.NewCoin(pool.PoolCoinDenom, sdk.ZeroInt())}
+		for i, denom := range pool.ReserveCoinDenoms {
+			empty.ReserveCoins[i] = sdk.NewCoin(denom, sdk.ZeroInt())
+		}
+		oldState, newState := empty, empty
+		if state, ok := oldStates[id]; ok {
+			oldState = state
+			diff.Old = &oldState
+		} else {
+			diff.Status = PoolStatusNew
+		}
+		if state, ok := newStates[id]; ok {
+			newState = state
+			diff.New = &newState
+		} else {
+			diff.Status = PoolStatusRemoved
+		}
+		for i := range diff.ReserveChanges {
+			diff.ReserveChanges[i] = newState.ReserveCoins[i].Amount.Sub(oldState.ReserveCoins[i].Amount)
+		}
+		diff.PoolCoinSupplyChange = newState.PoolCoinSupply.Amount.Sub(oldState.PoolCoinSupply.Amount)
+		if oldState.Price != nil && newState.Price != nil && oldState.Price.IsPositive() {
+			change := newState.Price.Quo(*oldState.Price).Sub(sdk.OneDec())
+			diff.PriceChange = &change
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].ID < diffs[j].ID })
+	return diffs
+}
+
+type PoolDiffReport struct {
+	SummaryMetadata
+	Pools []PoolDiff `json:"pools"`
+}
+
+// WritePoolDiffReport writes report to w in the given format.
+func WritePoolDiffReport(w io.Writer, format string, report PoolDiffReport) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatTable:
+		return writePoolDiffTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writePoolDiffTable(w io.Writer, report PoolDiffReport) error {
+	var numNew, numRemoved int
+	for _, diff := range report.Pools {
+		switch diff.Status {
+		case PoolStatusNew:
+			numNew++
+		case PoolStatusRemoved:
+			numRemoved++
+		}
+	}
+	fmt.Fprintf(w, "pools from block %d to %d: %d new, %d removed\n",
+		report.BeginHeight, report.EndHeight, numNew, numRemoved)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDENOM\tRESERVE\tCHANGE\tPOOL COIN SUPPLY\tCHANGE\tPRICE\tCHANGE\t")
+	for _, diff := range report.Pools {
+		var reserves [2]sdk.Coin
+		supply := sdk.ZeroInt()
+		var price, priceChange string
+		if diff.New != nil {
+			reserves = diff.New.ReserveCoins
+			supply = diff.New.PoolCoinSupply.Amount
+			if diff.New.Price != nil {
+				price = diff.New.Price.String()
+			}
+		} else {
+			for i, coin := range diff.Old.ReserveCoins {
+				reserves[i] = sdk.NewCoin(coin.Denom, sdk.ZeroInt())
+			}
+		}
+		if diff.PriceChange != nil {
+			priceChange = formatPercent(*diff.PriceChange) + "%"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			diff.ID, diff.Status, reserves[0].Denom, reserves[0].Amount, diff.ReserveChanges[0],
+			supply, diff.PoolCoinSupplyChange, price, priceChange)
+		fmt.Fprintf(tw, "\t\t%s\t%s\t%s\t\t\t\t\t\n", reserves[1].Denom, reserves[1].Amount, diff.ReserveChanges[1])
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// PriceRecord is the USD price of a whole token of Denom, whose smallest
+// unit is 10^-Exponent of a whole token (e.g. 6 for uatom).
+type PriceRecord struct {
+	Denom    string  `json:"denom"`
+	Price    float64 `json:"price"`
+	Exponent int     `json:"exponent"`
+}
+
+// PriceSource provides token prices.
+type PriceSource interface {
+	PriceRecords(ctx context.Context) ([]PriceRecord, error)
+}
+
+// NewPriceSource returns a source reading prices from uri, which is either
+// an http(s) URL serving a JSON array of PriceRecord, or the path of a local
+// .json or .csv file. CSV files have denom, price and optionally exponent
+// columns, with no header.
+func NewPriceSource(uri string) (PriceSource, error) {
+	switch {
+	case strings.HasPrefix(uri, "http://"), strings.HasPrefix(uri, "https://"):
+		return HTTPPriceSource{URL: uri, Client: http.DefaultClient}, nil
+	case strings.EqualFold(filepath.Ext(uri), ".json"), strings.EqualFold(filepath.Ext(uri), ".csv"):
+		return FilePriceSource{Path: uri}, nil
+	default:
+		return nil, fmt.Errorf("unsupported price source: %s", uri)
+	}
+}
+
+// FilePriceSource reads prices from a local JSON or CSV file.
+type FilePriceSource struct {
+	Path string
+}
+
+func (src FilePriceSource) PriceRecords(ctx context.Context) ([]PriceRecord, error) {
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(src.Path), ".csv") {
+		return readPriceRecordsCSV(f)
+	}
+	return readPriceRecordsJSON(f)
+}
+
+// HTTPPriceSource fetches prices as a JSON array of PriceRecord from URL.
+// A local file server serving a JSON price file can stand in for a real
+// price API.
+type HTTPPriceSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (src HTTPPriceSource) PriceRecords(ctx context.Context) ([]PriceRecord, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := src.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return readPriceRecordsJSON(resp.Body)
+}
+
+func readPriceRecordsJSON(r io.Reader) ([]PriceRecord, error) {
+	var records []PriceRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("decode prices: %w", err)
+	}
+	return records, nil
+}
+
+func readPriceRecordsCSV(r io.Reader) ([]PriceRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	var records []PriceRecord
+	for i, row := range rows {
+		if len(row) < 2 || len(row) > 3 {
+			return nil, fmt.Errorf("line %d: expected 2 or 3 columns, got %d", i+1, len(row))
+		}
+		record := PriceRecord{Denom: strings.TrimSpace(row[0])}
+		record.Price, err = strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: parse price: %w", i+1, err)
+		}
+		if len(row) == 3 {
+			record.Exponent, err = strconv.Atoi(strings.TrimSpace(row[2]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse exponent: %w", i+1, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Prices maps denoms to USD prices per smallest unit. IBC denoms without
+// their own price are priced by their base denom, found in the trace table.
+type Prices struct {
+	prices map[string]float64
+	traces map[string]string // (ibc denom) => (base denom)
+}
+
+func NewPrices(records []PriceRecord, traces []IBCTrace) *Prices {
+	p := &Prices{
+		prices: make(map[string]float64),
+		traces: make(map[string]string),
+	}
+	for _, record := range records {
+		p.prices[record.Denom] = record.Price / math.Pow10(record.Exponent)
+	}
+	for _, trace := range traces {
+		p.traces[trace.Denom] = trace.BaseDenom
+	}
+	return p
+}
+
+// LoadPrices loads prices from the source described by cfg.
+func LoadPrices(ctx context.Context, cfg PricingConfig) (*Prices, error) {
+	src, err := NewPriceSource(cfg.Source)
+	if err != nil {
+		return nil, err
+	}
+	records, err := src.PriceRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get prices: %w", err)
+	}
+	return NewPrices(records, cfg.IBCTraces), nil
+}
+
+// Price returns the USD price of the smallest unit of denom.
+func (p *Prices) Price(denom string) (float64, bool) {
+	if price, ok := p.prices[denom]; ok {
+		return price, true
+	}
+	if strings.HasPrefix(denom, "ibc/") {
+		if baseDenom, ok := p.traces[denom]; ok {
+			price, ok := p.prices[baseDenom]
+			return price, ok
+		}
+	}
+	return 0, false
+}
+
+// Value returns the USD value of coin.
+func (p *Prices) Value(coin sdk.Coin) (float64, bool) {
+	price, ok := p.Price(coin.Denom)
+	if !ok {
+		return 0, false
+	}
+	return intFloat64(coin.Amount) * price, true
+}
+
+func intFloat64(i sdk.Int) float64 {
+	if i.IsNil() {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(i.BigInt()).Float64()
+	return f
+}
+
+// PoolValue is the USD value of a pool summary.
+type PoolValue struct {
+	Reserve    float64 `json:"reserve"`
+	SwapVolume float64 `json:"swap_volume"`
+	Fees       float64 `json:"fees"`
+}
+
+// PoolValue returns the USD value of ps, or nil if any of the pool's denoms
+// has no price.
+func (p *Prices) PoolValue(ps *PoolSummary) *PoolValue {
+	var prices [2]float64
+	for i, coin := range ps.ReserveCoins {
+		price, ok := p.Price(coin.Denom)
+		if !ok {
+			return nil
+		}
+		prices[i] = price
+	}
+	value := &PoolValue{}
+	for i := range ps.ReserveCoins {
+		value.Reserve += intFloat64(ps.ReserveCoins[i].Amount) * prices[i]
+		// Swaps[i] offers the i-th denom and demands the other one.
+		value.SwapVolume += intFloat64(ps.Swaps[i].OfferCoin.Amount) * prices[i]
+		value.Fees += intFloat64(ps.Swaps[i].OfferCoinFee.Amount)*prices[i] +
+			intFloat64(ps.Swaps[i].DemandCoinFee.Amount)*prices[1-i]
+	}
+	return value
+}
+
+// TraderValue is the USD value of a trader summary. Coins without a price
+// are left out.
+type TraderValue struct {
+	Volume float64 `json:"volume"`
+	Fees   float64 `json:"fees"`
+}
+
+// TraderValue returns the USD value of ts.
+func (p *Prices) TraderValue(ts *TraderSummary) *TraderValue {
+	value := &TraderValue{}
+	for _, coin := range ts.Volume {
+		v, _ := p.Value(coin)
+		value.Volume += v
+	}
+	for _, coin := range ts.Fees {
+		v, _ := p.Value(coin)
+		value.Fees += v
+	}
+	return value
+}
+
+// DerivePrices prices the denoms without a price from the reserve ratios of
+// pools, starting from anchors. If anchors is empty, every priced denom is an
+// anchor. Each denom is priced through the path whose shallowest pool has the
+// highest USD reserve value, so that thin pools affect prices as little as
+// possible. It returns the sorted denoms that could not be priced.
+func (p *Prices) DerivePrices(pools []*PoolSummary, anchors []string) []string {
+	type edge struct {
+		to       string
+		reserves [2]sdk.Int // (from reserve, to reserve)
+	}
+	graph := make(map[string][]edge)
+	for _, ps := range pools {
+		x, y := ps.ReserveCoins[0], ps.ReserveCoins[1]
+		graph[x.Denom] = append(graph[x.Denom], edge{y.Denom, [2]sdk.Int{x.Amount, y.Amount}})
+		graph[y.Denom] = append(graph[y.Denom], edge{x.Denom, [2]sdk.Int{y.Amount, x.Amount}})
+	}
+
+	depths := make(map[string]float64) // (denom) => (depth of the best path so far)
+	pq := &priceQueue{}
+	push := func(denom string, depth float64) {
+		depths[denom] = depth
+		heap.Push(pq, priceQueueItem{denom, depth})
+	}
+	priced := make(map[string]bool) // denoms priced externally, whose prices are never overridden
+	for denom := range graph {
+		if _, ok := p.Price(denom); ok {
+			priced[denom] = true
+		}
+	}
+	if len(anchors) == 0 {
+		for denom := range priced {
+			anchors = append(anchors, denom)
+		}
+	}
+	for _, denom := range anchors {
+		if _, ok := p.Price(denom); ok {
+			push(denom, math.Inf(1))
+		}
+	}
+
+	done := make(map[string]bool)
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(priceQueueItem)
+		if done[item.denom] {
+			continue
+		}
+		done[item.denom] = true
+		price, _ := p.Price(item.denom)
+		for _, e := range graph[item.denom] {
+			if done[e.to] || !e.reserves[0].IsPositive() || !e.reserves[1].IsPositive() {
+				continue
+			}
+			depth := math.Min(item.depth, intFloat64(e.reserves[0])*price)
+			if d, reached := depths[e.to]; reached && d >= depth {
+				continue
+			}
+			// A denom priced externally keeps its own price, but still
+			// carries prices on to the denoms behind it.
+			if !priced[e.to] {
+				p.prices[e.to] = price * intFloat64(e.reserves[0]) / intFloat64(e.reserves[1])
+			}
+			push(e.to, depth)
+		}
+	}
+
+	var unpriced []string
+	for denom := range graph {
+		if _, ok := p.Price(denom); !ok {
+			unpriced = append(unpriced, denom)
+		}
+	}
+	sort.Strings(unpriced)
+	return unpriced
+}
+
+type priceQueueItem struct {
+	denom string
+	depth float64
+}
+
+// priceQueue is a max-heap of denoms by depth.
+type priceQueue []priceQueueItem
+
+func (pq priceQueue) Len() int            { return len(pq) }
+func (pq priceQueue) Less(i, j int) bool  { return pq[i].depth > pq[j].depth }
+func (pq priceQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *priceQueue) Push(x interface{}) { *pq = append(*pq, x.(priceQueueItem)) }
+func (pq *priceQueue) Pop() interface{} {
+	old := *pq
+	item := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestHTTPPriceSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/prices.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"denom":"uatom","price":12.5,"exponent":6},{"denom":"stake","price":0.5}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	src, err := NewPriceSource(srv.URL + "/prices.json")
+	if err != nil {
+		t.Fatalf("new price source: %v", err)
+	}
+	if _, ok := src.(HTTPPriceSource); !ok {
+		t.Fatalf("got %T, expected HTTPPriceSource", src)
+	}
+	records, err := src.PriceRecords(context.Background())
+	if err != nil {
+		t.Fatalf("get prices: %v", err)
+	}
+	expected := []PriceRecord{
+		{Denom: "uatom", Price: 12.5, Exponent: 6},
+		{Denom: "stake", Price: 0.5},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("got %v, expected %v", records, expected)
+	}
+
+	src = HTTPPriceSource{URL: srv.URL + "/missing.json", Client: srv.Client()}
+	_, err = src.PriceRecords(context.Background())
+	var serr HTTPStatusError
+	if !errors.As(err, &serr) || serr.StatusCode != http.StatusNotFound {
+		t.Errorf("got error %v, expected status %d", err, http.StatusNotFound)
+	}
+}
+
+func TestFilePriceSource(t *testing.T) {
+	dir := t.TempDir()
+	expected := []PriceRecord{
+		{Denom: "uatom", Price: 12.5, Exponent: 6},
+		{Denom: "stake", Price: 0.5},
+	}
+	for _, tc := range []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"prices.json", `[{"denom":"uatom","price":12.5,"exponent":6},{"denom":"stake","price":0.5}]`, false},
+		{"prices.csv", "uatom, 12.5, 6\nstake,0.5\n", false},
+		{"bad.json", `{"denom":"uatom"}`, true},
+		{"columns.csv", "uatom\n", true},
+		{"price.csv", "uatom,abc\n", true},
+		{"exponent.csv", "uatom,12.5,x\n", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			src, err := NewPriceSource(path)
+			if err != nil {
+				t.Fatalf("new price source: %v", err)
+			}
+			records, err := src.PriceRecords(context.Background())
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", records)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("get prices: %v", err)
+			}
+			if !reflect.DeepEqual(records, expected) {
+				t.Errorf("got %v, expected %v", records, expected)
+			}
+		})
+	}
+
+	if _, err := NewPriceSource(filepath.Join(dir, "prices.txt")); err == nil {
+		t.Error("expected error for unsupported price source")
+	}
+}
+
+func TestPricesIBCTrace(t *testing.T) {
+	prices := NewPrices(
+		[]PriceRecord{{Denom: "uatom", Price: 10, Exponent: 6}},
+		[]IBCTrace{
+			{Denom: "ibc/ATOM", BaseDenom: "uatom"},
+			{Denom: "ibc/OSMO", BaseDenom: "uosmo"},
+		},
+	)
+	for _, tc := range []struct {
+		denom string
+		price float64
+		ok    bool
+	}{
+		{"uatom", 10e-6, true},
+		{"ibc/ATOM", 10e-6, true},
+		{"ibc/OSMO", 0, false},
+		{"ibc/UNKNOWN", 0, false},
+		{"uosmo", 0, false},
+	} {
+		price, ok := prices.Price(tc.denom)
+		if ok != tc.ok || price != tc.price {
+			t.Errorf("%s: got %v, %v, expected %v, %v", tc.denom, price, ok, tc.price, tc.ok)
+		}
+	}
+}
+
+func TestDerivePrices(t *testing.T) {
+	pool := func(x string, xAmt int64, y string, yAmt int64) *PoolSummary {
+		return &PoolSummary{ReserveCoins: [2]sdk.Coin{sdk.NewInt64Coin(x, xAmt), sdk.NewInt64Coin(y, yAmt)}}
+	}
+	for _, tc := range []struct {
+		name     string
+		records  []PriceRecord
+		pools    []*PoolSummary
+		anchors  []string
+		prices   map[string]float64
+		unpriced []string
+	}{
+		{
+			"multi-hop",
+			[]PriceRecord{{Denom: "uusd", Price: 1}},
+			[]*PoolSummary{pool("uusd", 100, "uaaa", 200), pool("uaaa", 100, "ubbb", 400)},
+			nil,
+			map[string]float64{"uusd": 1, "uaaa": 0.5, "ubbb": 0.125},
+			nil,
+		},
+		{
+			"deeper path",
+			[]PriceRecord{{Denom: "uusd", Price: 1}},
+			[]*PoolSummary{pool("uusd", 10, "ubbb", 10), pool("uusd", 1000, "uaaa", 1000), pool("uaaa", 1000, "ubbb", 2000)},
+			nil,
+			map[string]float64{"uusd": 1, "uaaa": 1, "ubbb": 0.5},
+			nil,
+		},
+		{
+			"zero reserve",
+			[]PriceRecord{{Denom: "uusd", Price: 1}},
+			[]*PoolSummary{pool("uusd", 0, "uaaa", 100), pool("uusd", 100, "ubbb", 0)},
+			nil,
+			map[string]float64{"uusd": 1},
+			[]string{"uaaa", "ubbb"},
+		},
+		{
+			"implicit anchors",
+			[]PriceRecord{{Denom: "uusd", Price: 1}, {Denom: "ueur", Price: 2}},
+			[]*PoolSummary{pool("uusd", 100, "uaaa", 100), pool("ueur", 1000, "uaaa", 1000)},
+			nil,
+			map[string]float64{"uusd": 1, "ueur": 2, "uaaa": 2},
+			nil,
+		},
+		{
+			"explicit anchors",
+			[]PriceRecord{{Denom: "uusd", Price: 1}, {Denom: "ueur", Price: 2}},
+			[]*PoolSummary{pool("uusd", 100, "uaaa", 100), pool("ueur", 1000, "uaaa", 1000)},
+			[]string{"uusd"},
+			map[string]float64{"uusd": 1, "ueur": 2, "uaaa": 1},
+			nil,
+		},
+		{
+			"through priced denom",
+			[]PriceRecord{{Denom: "uusd", Price: 1}, {Denom: "ueur", Price: 2}},
+			[]*PoolSummary{pool("uusd", 100, "ueur", 100), pool("ueur", 100, "uaaa", 100)},
+			[]string{"uusd"},
+			map[string]float64{"uusd": 1, "ueur": 2, "uaaa": 2},
+			nil,
+		},
+		{
+			"unpriced",
+			[]PriceRecord{{Denom: "uusd", Price: 1}},
+			[]*PoolSummary{pool("uusd", 100, "uaaa", 100), pool("uyyy", 100, "uxxx", 100)},
+			nil,
+			map[string]float64{"uusd": 1, "uaaa": 1},
+			[]string{"uxxx", "uyyy"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prices := NewPrices(tc.records, nil)
+			unpriced := prices.DerivePrices(tc.pools, tc.anchors)
+			if !reflect.DeepEqual(unpriced, tc.unpriced) {
+				t.Errorf("got unpriced %v, expected %v", unpriced, tc.unpriced)
+			}
+			if !reflect.DeepEqual(prices.prices, tc.prices) {
+				t.Errorf("got %v, expected %v", prices.prices, tc.prices)
+			}
+		})
+	}
+}
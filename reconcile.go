@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+type reserveState struct {
+	height   int64
+	reserves [2]sdk.Int
+}
+
+// ReserveReplay tracks pool reserves by applying the reserve changes of
+// swap, deposit and withdrawal events to known starting reserves. Amounts
+// may go negative if events are missing.
+type ReserveReplay struct {
+	pools   map[uint64]liquiditytypes.Pool
+	begin   map[uint64]*PoolSummary
+	states  map[uint64][]reserveState // (pool id) => (reserves after each height with events)
+	initial map[uint64][2]sdk.Int
+	counts  map[uint64]int
+}
+
+// NewReserveReplay starts a replay of pools from their reserves in begin, as
+// loaded by LoadPoolReserves.
+func NewReserveReplay(pools []liquiditytypes.Pool, begin []*PoolSummary) *ReserveReplay {
+	r := &ReserveReplay{
+		pools:   make(map[uint64]liquiditytypes.Pool),
+		begin:   make(map[uint64]*PoolSummary),
+		states:  make(map[uint64][]reserveState),
+		initial: make(map[uint64][2]sdk.Int),
+		counts:  make(map[uint64]int),
+	}
+	for _, pool := range pools {
+		r.pools[pool.Id] = pool
+	}
+	for _, ps := range begin {
+		r.begin[ps.ID] = ps
+		r.initial[ps.ID] = [2]sdk.Int{ps.ReserveCoins[0].Amount, ps.ReserveCoins[1].Amount}
+	}
+	return r
+}
+
+// Add applies the reserve changes of evt. Events must be added in the
+// order they were emitted. Events of other pools, failed events and events
+// not moving reserves are ignored.
+func (r *ReserveReplay) Add(evt LiquidityEvent, _ time.Time) error {
+	ps, ok := r.begin[evt.GetPoolID()]
+	if !ok {
+		return nil
+	}
+	var in, out sdk.Coins
+	switch evt := evt.(type) {
+	case *SwapTransactedEvent:
+		if !evt.Success {
+			return nil
+		}
+		in = sdk.NewCoins(evt.TransactedCoin).Add(evt.ExchangedOfferCoinFee)
+		out = sdk.NewCoins(evt.ExchangedDemandCoin)
+	case *DepositToPoolEvent:
+		if !evt.Success {
+			return nil
+		}
+		in = evt.AcceptedCoins
+	case *WithdrawFromPoolEvent:
+		if !evt.Success {
+			return nil
+		}
+		out = evt.WithdrawCoins
+	default:
+		return nil
+	}
+	for _, coin := range append(in, out...) {
+		if coin.Denom != ps.ReserveCoins[0].Denom && coin.Denom != ps.ReserveCoins[1].Denom {
+			return fmt.Errorf("coin %s of %s event at height %d is not a reserve coin of pool %d",
+				coin, evt.GetType(), evt.GetHeight(), ps.ID)
+		}
+	}
+	states := r.states[ps.ID]
+	if len(states) == 0 || states[len(states)-1].height != evt.GetHeight() {
+		states = append(states, reserveState{evt.GetHeight(), r.Reserves(ps.ID)})
+	}
+	state := &states[len(states)-1]
+	for i, coin := range ps.ReserveCoins {
+		state.reserves[i] = state.reserves[i].Add(in.AmountOf(coin.Denom)).Sub(out.AmountOf(coin.Denom))
+	}
+	r.states[ps.ID] = states
+	r.counts[ps.ID]++
+	return nil
+}
+
+// Reserves returns the replayed reserves of a pool after all events added,
+// in the order of its reserve coin denoms.
+func (r *ReserveReplay) Reserves(poolID uint64) [2]sdk.Int {
+	states := r.states[poolID]
+	if len(states) == 0 {
+		return r.initial[poolID]
+	}
+	return states[len(states)-1].reserves
+}
+
+// ReservesAt returns the replayed reserves of a pool at the end of height.
+func (r *ReserveReplay) ReservesAt(poolID uint64, height int64) [2]sdk.Int {
+	states := r.states[poolID]
+	i := sort.Search(len(states), func(i int) bool { return states[i].height > height })
+	if i == 0 {
+		return r.initial[poolID]
+	}
+	return states[i-1].reserves
+}
+
+// ReserveDrift compares a replayed reserve with the actual one. Drift is
+// actual minus replayed.
+type ReserveDrift struct {
+	Denom    string  `json:"denom"`
+	Replayed sdk.Int `json:"replayed"`
+	Actual   sdk.Int `json:"actual"`
+	Drift    sdk.Int `json:"drift"`
+}
+
+// PoolReconciliation is the result of replaying the events of a pool.
+// FirstMismatchHeight is the first height at which replayed and actual
+// reserves differ, or zero if they match at the end.
+type PoolReconciliation struct {
+	PoolID              uint64          `json:"pool_id"`
+	NumEvents           int             `json:"num_events"`
+	BeginReserves       [2]sdk.Coin     `json:"begin_reserves"`
+	Reserves            [2]ReserveDrift `json:"reserves"`
+	Matches             bool            `json:"matches"`
+	FirstMismatchHeight int64           `json:"first_mismatch_height,omitempty"`
+}
+
+// Reconcile compares the replayed reserves of every pool with actual, the
+// reserves at endHeight. For mismatching pools, the first mismatching
+// height in (beginHeight, endHeight] is found by bisection, assuming the
+// reserves don't match again once they drifted apart.
+func (r *ReserveReplay) Reconcile(ctx context.Context, c *Client, actual []*PoolSummary, beginHeight, endHeight int64) ([]PoolReconciliation, error) {
+	var results []PoolReconciliation
+	for _, end := range actual {
+		ps, ok := r.begin[end.ID]
+		if !ok {
+			continue
+		}
+		replayed := r.Reserves(ps.ID)
+		result := PoolReconciliation{
+			PoolID:        ps.ID,
+			NumEvents:     r.counts[ps.ID],
+			BeginReserves: ps.ReserveCoins,
+			Matches:       true,
+		}
+		for i, coin := range end.ReserveCoins {
+			result.Reserves[i] = ReserveDrift{
+				Denom:    coin.Denom,
+				Replayed: replayed[i],
+				Actual:   coin.Amount,
+				Drift:    coin.Amount.Sub(replayed[i]),
+			}
+			if !result.Reserves[i].Drift.IsZero() {
+				result.Matches = false
+			}
+		}
+		if !result.Matches {
+			h, err := r.firstMismatchHeight(ctx, c, ps, beginHeight, endHeight)
+			if err != nil {
+				return nil, fmt.Errorf("find first mismatch of pool %d: %w", ps.ID, err)
+			}
+			result.FirstMismatchHeight = h
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (r *ReserveReplay) firstMismatchHeight(ctx context.Context, c *Client, ps *PoolSummary, beginHeight, endHeight int64) (int64, error) {
+	var err error
+	n := int(endHeight - beginHeight)
+	i := sort.Search(n, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		height := beginHeight + 1 + int64(i)
+		replayed := r.ReservesAt(ps.ID, height)
+		for j, coin := range ps.ReserveCoins {
+			var balance sdk.Coin
+			balance, err = c.Balance(ctx, r.pools[ps.ID].ReserveAccountAddress, coin.Denom, WithBlockHeight(height))
+			if err != nil {
+				return true
+			}
+			if !balance.Amount.Equal(replayed[j]) {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get balance: %w", err)
+	}
+	return beginHeight + 1 + int64(i), nil
+}
+
+type ReconcileReport struct {
+	SummaryMetadata
+	NumMismatches int                  `json:"num_mismatches"`
+	Pools         []PoolReconciliation `json:"pools"`
+}
+
+// WriteReconcileReport writes report to w in the given format.
+func WriteReconcileReport(w io.Writer, format string, report ReconcileReport) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatTable:
+		return writeReconcileTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeReconcileTable(w io.Writer, report ReconcileReport) error {
+	fmt.Fprintf(w, "reserves replayed from block %d to %d: %d of %d pool(s) mismatch\n",
+		report.BeginHeight, report.EndHeight, report.NumMismatches, len(report.Pools))
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ID\tEVENTS\tDENOM\tREPLAYED\tACTUAL\tDRIFT\tFIRST MISMATCH\t")
+	for _, pr := range report.Pools {
+		var firstMismatch string
+		if !pr.Matches {
+			firstMismatch = fmt.Sprint(pr.FirstMismatchHeight)
+		}
+		for i, rd := range pr.Reserves {
+			if i == 0 {
+				fmt.Fprintf(tw, "%d\t%d\t", pr.PoolID, pr.NumEvents)
+			} else {
+				fmt.Fprint(tw, "\t\t")
+				firstMismatch = ""
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", rd.Denom, rd.Replayed, rd.Actual, rd.Drift, firstMismatch)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retry calls fn until it succeeds, returns a permanent error or the maximum
+// number of attempts is reached. Each attempt gets its own deadline when
+// RetryConfig.CallTimeout is set.
+func (c *Client) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	cfg := c.cfg.Retry
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	backoff := cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.call(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !IsRetriable(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		select {
+		case <-time.After(jitter(backoff)):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+		if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
+
+func (c *Client) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if c.cfg.Retry.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Retry.CallTimeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// jitter returns a random duration in [d/2, d).
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// IsRetriable reports whether err is a transient error that is worth retrying.
+// Errors reported by the node itself, such as querying a pruned height or
+// an invalid query, are considered permanent.
+func IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+			return true
+		default:
+			return false
+		}
+	}
+	var rpcErr *rpctypes.RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var statusErr HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	// Only timeouts and refused or reset connections are transient. Other
+	// network errors, such as an invalid URL or a bad certificate, are not.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// HTTPStatusError is returned by the RPC transport when the server responds
+// with an unexpected HTTP status code.
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (err HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected http status: %d %s", err.StatusCode, http.StatusText(err.StatusCode))
+}
+
+// StatusCheckRoundTripper turns 5xx and 429 responses into HTTPStatusError,
+// since the RPC client would otherwise fail with an opaque decoding error.
+type StatusCheckRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (rt StatusCheckRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
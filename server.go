@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Server serves the statistics of the commands over HTTP. Responses for a
+// height range are computed once and cached, since events of past blocks
+// never change.
+type Server struct {
+	ctx          context.Context
+	client       *Client
+	src          EventSource
+	pricing      PricingConfig
+	pricingFlags PricingFlags
+	cache        *responseCache
+	computations chan struct{}
+}
+
+// NewServer returns a server computing responses under ctx, which should
+// outlive the requests being served. At most maxComputations responses are
+// computed at once, since a range defaulting to the latest height misses the
+// cache on every new block.
+func NewServer(ctx context.Context, c *Client, src EventSource, pricing PricingConfig, pricingFlags PricingFlags, cacheSize, maxComputations int) *Server {
+	return &Server{
+		ctx:          ctx,
+		client:       c,
+		src:          src,
+		pricing:      pricing,
+		pricingFlags: pricingFlags,
+		cache:        newResponseCache(cacheSize),
+		computations: make(chan struct{}, maxComputations),
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pools", s.handlePools)
+	mux.HandleFunc("/pools/", s.handlePoolSummary)
+	mux.HandleFunc("/traders", s.handleTraders)
+	mux.HandleFunc("/timeseries", s.handleTimeSeries)
+	return mux
+}
+
+// httpError is an error with the status code to respond with.
+type httpError struct {
+	code int
+	err  error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+// PoolInfo is a pool along with its reserves.
+type PoolInfo struct {
+	ID                    uint64      `json:"id"`
+	PoolCoinDenom         string      `json:"pool_coin_denom"`
+	ReserveAccountAddress string      `json:"reserve_account"`
+	ReserveCoins          [2]sdk.Coin `json:"reserve_coins"`
+}
+
+type PoolsResponse struct {
+	Height int64      `json:"height"`
+	Pools  []PoolInfo `json:"pools"`
+}
+
+// handlePools serves the pools and their reserves at the height query
+// parameter, or at the latest height.
+func (s *Server) handlePools(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func() (interface{}, error) {
+		var height int64
+		if v := req.URL.Query().Get("height"); v != "" {
+			h, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || h <= 0 {
+				return nil, badRequest("invalid height: %s", v)
+			}
+			height = h
+		} else {
+			h, err := s.client.LatestBlockHeight(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("get latest block height: %w", err)
+			}
+			height = h
+		}
+		return s.cached(req.Context(), fmt.Sprintf("pools:%d", height), func() (interface{}, error) {
+			pools, err := s.client.Pools(s.ctx, WithBlockHeight(height))
+			if err != nil {
+				return nil, fmt.Errorf("get pools: %w", err)
+			}
+			summaries, err := loadPoolReserves(s.ctx, s.client, pools, height, func() {})
+			if err != nil {
+				return nil, err
+			}
+			resp := PoolsResponse{Height: height, Pools: []PoolInfo{}}
+			for i, pool := range pools {
+				resp.Pools = append(resp.Pools, PoolInfo{
+					ID:                    pool.Id,
+					PoolCoinDenom:         pool.PoolCoinDenom,
+					ReserveAccountAddress: pool.ReserveAccountAddress,
+					ReserveCoins:          summaries[i].ReserveCoins,
+				})
+			}
+			return resp, nil
+		})
+	})
+}
+
+type PoolSummaryResponse struct {
+	SummaryMetadata
+	Pool *PoolSummary `json:"pool"`
+}
+
+// handlePoolSummary serves /pools/{id}/summary, the summary of a pool over
+// the height range given by the from and to query parameters.
+func (s *Server) handlePoolSummary(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func() (interface{}, error) {
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/pools/"), "/")
+		if len(parts) != 2 || parts[1] != "summary" {
+			return nil, &httpError{http.StatusNotFound, fmt.Errorf("not found: %s", req.URL.Path)}
+		}
+		poolID, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, badRequest("invalid pool id: %s", parts[0])
+		}
+		r, err := s.resolveRange(req.Context(), req.URL.Query())
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.cached(req.Context(), rangeCacheKey("summary", r), func() (interface{}, error) {
+			return s.summary(r)
+		})
+		if err != nil {
+			return nil, err
+		}
+		report := v.(SummaryReport)
+		for _, ps := range report.Pools {
+			if ps.ID == poolID {
+				return PoolSummaryResponse{SummaryMetadata: report.SummaryMetadata, Pool: ps}, nil
+			}
+		}
+		return nil, &httpError{http.StatusNotFound, fmt.Errorf("pool id not found: %d", poolID)}
+	})
+}
+
+// summary summarizes the pools existing at the end of r, like SummaryCmd.
+func (s *Server) summary(r HeightRange) (SummaryReport, error) {
+	pools, err := s.client.Pools(s.ctx, WithBlockHeight(r.EndHeight))
+	if err != nil {
+		return SummaryReport{}, fmt.Errorf("get pools: %w", err)
+	}
+	summaries, err := loadPoolReserves(s.ctx, s.client, pools, r.EndHeight, func() {})
+	if err != nil {
+		return SummaryReport{}, err
+	}
+	if _, err := SummarizeEvents(s.ctx, s.src, summaries, r.BeginHeight, r.EndHeight); err != nil {
+		return SummaryReport{}, err
+	}
+	prices, unpricedDenoms, err := s.pricingFlags.Load(s.ctx, s.pricing, summaries)
+	if err != nil {
+		return SummaryReport{}, err
+	}
+	if prices != nil {
+		for _, ps := range summaries {
+			ps.Value = prices.PoolValue(ps)
+		}
+	}
+	return SummaryReport{
+		SummaryMetadata: newSummaryMetadata(r, unpricedDenoms),
+		Pools:           summaries,
+	}, nil
+}
+
+// handleTraders serves the traders over the height range given by the from
+// and to query parameters, sorted by the sort parameter and limited to the
+// limit parameter.
+func (s *Server) handleTraders(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func() (interface{}, error) {
+		q := req.URL.Query()
+		sortBy := q.Get("sort")
+		switch sortBy {
+		case "":
+			sortBy = TraderSortSwaps
+		case TraderSortSwaps, TraderSortVolume, TraderSortFees, TraderSortPools, TraderSortFirst, TraderSortLast:
+		default:
+			return nil, badRequest("unknown sort key: %s", sortBy)
+		}
+		var limit int
+		if v := q.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, badRequest("invalid limit: %s", v)
+			}
+			limit = n
+		}
+		r, err := s.resolveRange(req.Context(), q)
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.cached(req.Context(), rangeCacheKey("traders", r), func() (interface{}, error) {
+			return s.traders(r)
+		})
+		if err != nil {
+			return nil, err
+		}
+		report := v.(TraderReport)
+		// The cached report is shared, so sort a copy of it.
+		traders := append([]*TraderSummary(nil), report.Traders...)
+		if (sortBy == TraderSortVolume || sortBy == TraderSortFees) && len(traders) > 0 && traders[0].Value == nil {
+			return nil, badRequest("sorting by %s requires prices", sortBy)
+		}
+		if err := SortTraders(traders, sortBy); err != nil {
+			return nil, err
+		}
+		if limit > 0 && len(traders) > limit {
+			traders = traders[:limit]
+		}
+		report.Traders = traders
+		return report, nil
+	})
+}
+
+// traders aggregates the traders of r, like TradersCmd.
+func (s *Server) traders(r HeightRange) (TraderReport, error) {
+	var summaries []*PoolSummary
+	if s.pricingFlags.Deriving(s.pricing) {
+		pools, err := s.client.Pools(s.ctx, WithBlockHeight(r.EndHeight))
+		if err != nil {
+			return TraderReport{}, fmt.Errorf("get pools: %w", err)
+		}
+		summaries, err = loadPoolReserves(s.ctx, s.client, pools, r.EndHeight, func() {})
+		if err != nil {
+			return TraderReport{}, err
+		}
+	}
+	prices, unpricedDenoms, err := s.pricingFlags.Load(s.ctx, s.pricing, summaries)
+	if err != nil {
+		return TraderReport{}, err
+	}
+	traders := NewTraders()
+	if err := s.src.IterateEvents(s.ctx, SwapEventTypes, r.BeginHeight, r.EndHeight, false, traders.Add); err != nil {
+		return TraderReport{}, fmt.Errorf("iterate events: %w", err)
+	}
+	list := traders.Traders()
+	if prices != nil {
+		for _, ts := range list {
+			ts.Value = prices.TraderValue(ts)
+		}
+	}
+	return TraderReport{
+		SummaryMetadata: newSummaryMetadata(r, unpricedDenoms),
+		Traders:         list,
+	}, nil
+}
+
+// handleTimeSeries serves the time series over the height range given by
+// the from and to query parameters, bucketed by the bucket parameter.
+func (s *Server) handleTimeSeries(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func() (interface{}, error) {
+		q := req.URL.Query()
+		bucket := 24 * time.Hour
+		if v := q.Get("bucket"); v != "" {
+			d, err := ParseDuration(v)
+			if err != nil || d <= 0 {
+				return nil, badRequest("invalid bucket: %s", v)
+			}
+			bucket = d
+		}
+		r, err := s.resolveRange(req.Context(), q)
+		if err != nil {
+			return nil, err
+		}
+		return s.cached(req.Context(), rangeCacheKey("timeseries:"+bucket.String(), r), func() (interface{}, error) {
+			pools, err := s.client.Pools(s.ctx, WithBlockHeight(r.EndHeight))
+			if err != nil {
+				return nil, fmt.Errorf("get pools: %w", err)
+			}
+			ts := NewTimeSeries(pools, bucket)
+			if err := s.src.IterateEvents(s.ctx, SwapEventTypes, r.BeginHeight, r.EndHeight, true, ts.Add); err != nil {
+				return nil, fmt.Errorf("iterate events: %w", err)
+			}
+			return TimeSeriesReport{
+				SummaryMetadata: newSummaryMetadata(r, nil),
+				Bucket:          bucket.String(),
+				Points:          ts.Points(),
+			}, nil
+		})
+	})
+}
+
+// resolveRange resolves the from and to query parameters, each a block
+// height or a time as accepted by --since and --until, like HeightRangeFlags.
+func (s *Server) resolveRange(ctx context.Context, q url.Values) (HeightRange, error) {
+	f := HeightRangeFlags{BeginHeight: 1}
+	now := time.Now()
+	for _, p := range []struct {
+		name   string
+		height *int64
+		time   *time.Time
+	}{
+		{"from", &f.BeginHeight, &f.since},
+		{"to", &f.EndHeight, &f.until},
+	} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		if h, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if h <= 0 {
+				return HeightRange{}, badRequest("invalid %s: %s", p.name, v)
+			}
+			*p.height = h
+			if p.name == "from" {
+				f.beginSet = true
+			}
+			continue
+		}
+		t, err := ParseTime(v, now)
+		if err != nil {
+			return HeightRange{}, badRequest("invalid %s: %s", p.name, v)
+		}
+		*p.time = t
+	}
+	r, err := f.Resolve(ctx, s.client, s.src)
+	if err != nil {
+		var berr *BeforeHistoryError
+		if errors.As(err, &berr) {
+			return HeightRange{}, badRequest("%v", err)
+		}
+		return HeightRange{}, err
+	}
+	return r, nil
+}
+
+// cached returns the cached response for key, computing it if needed once a
+// computation slot is free. The computation is shared by the requests for
+// key and runs under s.ctx, so a request going away doesn't fail it for the
+// others; waiting for the response stops when ctx is done.
+func (s *Server) cached(ctx context.Context, key string, compute func() (interface{}, error)) (interface{}, error) {
+	return s.cache.Get(ctx, key, func() (interface{}, error) {
+		select {
+		case s.computations <- struct{}{}:
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		}
+		defer func() { <-s.computations }()
+		return compute()
+	})
+}
+
+func rangeCacheKey(name string, r HeightRange) string {
+	return fmt.Sprintf("%s:%d-%d", name, r.BeginHeight, r.EndHeight)
+}
+
+func newSummaryMetadata(r HeightRange, unpricedDenoms []string) SummaryMetadata {
+	return SummaryMetadata{
+		BeginHeight:    r.BeginHeight,
+		EndHeight:      r.EndHeight,
+		BeginTime:      r.BeginTime,
+		EndTime:        r.EndTime,
+		GeneratedAt:    time.Now().UTC(),
+		UnpricedDenoms: unpricedDenoms,
+	}
+}
+
+// serve writes the result of handle as JSON.
+func (s *Server) serve(w http.ResponseWriter, req *http.Request, handle func() (interface{}, error)) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	v, err := handle()
+	if err != nil {
+		code := http.StatusInternalServerError
+		var herr *httpError
+		if errors.As(err, &herr) {
+			code = herr.code
+		} else if req.Context().Err() != nil || s.ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			// The request went away or the server is shutting down, which
+			// is not an internal error.
+			code = http.StatusServiceUnavailable
+		} else {
+			log.Printf("%s %s: %v", req.Method, req.URL, err)
+		}
+		writeJSON(w, code, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("write response: %v", err)
+	}
+}
+
+// responseCache caches up to size computed responses, evicting the oldest
+// first. Concurrent requests for the same key share a single computation,
+// and failed computations are not cached.
+type responseCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*cacheEntry
+	keys    []string // completed keys, oldest first
+}
+
+type cacheEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newResponseCache(size int) *responseCache {
+	return &responseCache{size: size, entries: make(map[string]*cacheEntry)}
+}
+
+// Get returns the response for key, starting compute in the background if
+// no computation for key is cached or in progress. Waiting for the response
+// stops when ctx is done, while the computation goes on.
+func (c *responseCache) Get(ctx context.Context, key string, compute func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &cacheEntry{done: make(chan struct{})}
+		c.entries[key] = e
+		go c.compute(key, e, compute)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.value, e.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *responseCache) compute(key string, e *cacheEntry, compute func() (interface{}, error)) {
+	e.value, e.err = compute()
+	close(e.done)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.err != nil {
+		delete(c.entries, key)
+		return
+	}
+	c.keys = append(c.keys, key)
+	for len(c.keys) > c.size {
+		delete(c.entries, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// EventSource provides decoded liquidity end block events.
+type EventSource interface {
+	// IterateEvents calls fn for each event of eventTypes emitted in
+	// [beginHeight, endHeight] in the order they were emitted. The block time
+	// passed to fn is only set if withTime is true.
+	IterateEvents(ctx context.Context, eventTypes []string, beginHeight, endHeight int64, withTime bool, fn func(evt LiquidityEvent, blockTime time.Time) error) error
+	// IterateAddressEvents calls fn for each event emitted in
+	// [beginHeight, endHeight] that involves addr, as returned by
+	// EventAddress, with the block time always set.
+	IterateAddressEvents(ctx context.Context, addr string, beginHeight, endHeight int64, fn func(evt LiquidityEvent, blockTime time.Time) error) error
+	// LatestHeight returns the latest height whose events are available.
+	LatestHeight(ctx context.Context) (int64, error)
+	Close() error
+}
+
+// NodeEventSource fetches events from the node.
+type NodeEventSource struct {
+	client      *Client
+	concurrency int
+}
+
+func NewNodeEventSource(c *Client, concurrency int) *NodeEventSource {
+	return &NodeEventSource{client: c, concurrency: concurrency}
+}
+
+func (src *NodeEventSource) IterateEvents(ctx context.Context, eventTypes []string, beginHeight, endHeight int64, withTime bool, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	heights, err := src.client.SearchEventBlockHeights(ctx, eventTypes, beginHeight, endHeight)
+	if err != nil {
+		return fmt.Errorf("search block heights: %w", err)
+	}
+
+	typeSet := make(map[string]struct{})
+	for _, eventType := range eventTypes {
+		typeSet[eventType] = struct{}{}
+	}
+
+	bar := progressbar.Default(int64(len(heights)))
+
+	handleBlock := func(block BlockEvents) error {
+		evts, err := DecodeEvents(block.Height, block.Events)
+		if err != nil {
+			return err
+		}
+		for _, evt := range evts {
+			if _, ok := typeSet[evt.GetType()]; !ok {
+				continue
+			}
+			if err := fn(evt, block.Time); err != nil {
+				return err
+			}
+		}
+		_ = bar.Add(1)
+		return nil
+	}
+	if withTime {
+		return src.client.IterateBlockEvents(ctx, heights, src.concurrency, handleBlock)
+	}
+	return src.client.IterateEndBlockEvents(ctx, heights, src.concurrency, func(height int64, events []abcitypes.Event) error {
+		return handleBlock(BlockEvents{Height: height, Events: events})
+	})
+}
+
+func (src *NodeEventSource) IterateAddressEvents(ctx context.Context, addr string, beginHeight, endHeight int64, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	heights, err := src.client.SearchAddressBlockHeights(ctx, addr, beginHeight, endHeight)
+	if err != nil {
+		return fmt.Errorf("search block heights: %w", err)
+	}
+	return src.client.IterateBlockEvents(ctx, heights, src.concurrency, func(block BlockEvents) error {
+		evts, err := DecodeEvents(block.Height, block.Events)
+		if err != nil {
+			return err
+		}
+		for _, evt := range evts {
+			if evtAddr, ok := EventAddress(evt); !ok || evtAddr != addr {
+				continue
+			}
+			if err := fn(evt, block.Time); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (src *NodeEventSource) LatestHeight(ctx context.Context) (int64, error) {
+	return src.client.LatestBlockHeight(ctx)
+}
+
+func (src *NodeEventSource) Close() error {
+	return nil
+}
+
+// StoreEventSource reads events from a local store filled by the index command.
+type StoreEventSource struct {
+	store *Store
+}
+
+func NewStoreEventSource(s *Store) *StoreEventSource {
+	return &StoreEventSource{store: s}
+}
+
+func (src *StoreEventSource) IterateEvents(ctx context.Context, eventTypes []string, beginHeight, endHeight int64, withTime bool, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	if err := src.checkRange(beginHeight, endHeight); err != nil {
+		return err
+	}
+	return src.store.IterateEvents(eventTypes, beginHeight, endHeight, fn)
+}
+
+func (src *StoreEventSource) IterateAddressEvents(ctx context.Context, addr string, beginHeight, endHeight int64, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	if err := src.checkRange(beginHeight, endHeight); err != nil {
+		return err
+	}
+	return src.store.IterateAddressEvents(addr, beginHeight, endHeight, fn)
+}
+
+// checkRange checks that the store covers [beginHeight, endHeight].
+func (src *StoreEventSource) checkRange(beginHeight, endHeight int64) error {
+	firstHeight, lastHeight, ok, err := src.store.IndexedRange()
+	if err != nil {
+		return fmt.Errorf("get indexed range: %w", err)
+	}
+	if !ok || beginHeight < firstHeight || endHeight > lastHeight {
+		return fmt.Errorf("store does not cover heights %d-%d; run index first", beginHeight, endHeight)
+	}
+	return nil
+}
+
+func (src *StoreEventSource) LatestHeight(ctx context.Context) (int64, error) {
+	_, lastHeight, ok, err := src.store.IndexedRange()
+	if err != nil {
+		return 0, fmt.Errorf("get indexed range: %w", err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("store is empty; run index first")
+	}
+	return lastHeight, nil
+}
+
+func (src *StoreEventSource) Close() error {
+	return src.store.Close()
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+	_ "github.com/mattn/go-sqlite3"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS state (
+	key   TEXT PRIMARY KEY,
+	value INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS blocks (
+	height INTEGER PRIMARY KEY,
+	time   INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	height     INTEGER NOT NULL REFERENCES blocks (height),
+	idx        INTEGER NOT NULL,
+	type       TEXT NOT NULL,
+	pool_id    INTEGER NOT NULL,
+	attributes TEXT NOT NULL,
+	UNIQUE (height, idx)
+);
+CREATE TABLE IF NOT EXISTS swaps (
+	event_id          INTEGER PRIMARY KEY REFERENCES events (id),
+	height            INTEGER NOT NULL,
+	pool_id           INTEGER NOT NULL,
+	success           INTEGER NOT NULL,
+	requester         TEXT NOT NULL,
+	offer_denom       TEXT NOT NULL,
+	offer_amount      TEXT NOT NULL,
+	offer_fee_amount  TEXT NOT NULL,
+	demand_denom      TEXT NOT NULL,
+	demand_amount     TEXT NOT NULL,
+	demand_fee_amount TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS deposits (
+	event_id         INTEGER PRIMARY KEY REFERENCES events (id),
+	height           INTEGER NOT NULL,
+	pool_id          INTEGER NOT NULL,
+	success          INTEGER NOT NULL,
+	depositor        TEXT NOT NULL,
+	accepted_coins   TEXT NOT NULL,
+	refunded_coins   TEXT NOT NULL,
+	pool_coin_denom  TEXT NOT NULL,
+	pool_coin_amount TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS withdrawals (
+	event_id           INTEGER PRIMARY KEY REFERENCES events (id),
+	height             INTEGER NOT NULL,
+	pool_id            INTEGER NOT NULL,
+	success            INTEGER NOT NULL,
+	withdrawer         TEXT NOT NULL,
+	pool_coin_denom    TEXT NOT NULL,
+	pool_coin_amount   TEXT NOT NULL,
+	withdraw_coins     TEXT NOT NULL,
+	withdraw_fee_coins TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_pool_id_height ON events (pool_id, height);
+CREATE INDEX IF NOT EXISTS swaps_requester ON swaps (requester);
+CREATE INDEX IF NOT EXISTS deposits_depositor ON deposits (depositor);
+CREATE INDEX IF NOT EXISTS withdrawals_withdrawer ON withdrawals (withdrawer);
+`
+
+const (
+	stateKeyBeginHeight = "begin_height"
+	stateKeyLastHeight  = "last_height"
+)
+
+// Store is a local SQLite database of liquidity end block events.
+type Store struct {
+	db *sql.DB
+}
+
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(storeSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) state(key string) (int64, bool, error) {
+	var v int64
+	err := s.db.QueryRow(`SELECT value FROM state WHERE key = ?`, key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+func setState(tx *sql.Tx, key string, value int64) error {
+	_, err := tx.Exec(`INSERT INTO state (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
+
+// IndexedRange returns the range of heights that have been indexed.
+// ok is false if nothing has been indexed yet.
+func (s *Store) IndexedRange() (beginHeight, lastHeight int64, ok bool, err error) {
+	beginHeight, ok, err = s.state(stateKeyBeginHeight)
+	if err != nil || !ok {
+		return 0, 0, false, err
+	}
+	lastHeight, ok, err = s.state(stateKeyLastHeight)
+	if err != nil || !ok {
+		return 0, 0, false, err
+	}
+	return beginHeight, lastHeight, true, nil
+}
+
+// StartIndexing records beginHeight as the first indexed height, if nothing
+// has been indexed yet.
+func (s *Store) StartIndexing(beginHeight int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO state (key, value) VALUES (?, ?)`, stateKeyBeginHeight, beginHeight); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO state (key, value) VALUES (?, ?)`, stateKeyLastHeight, beginHeight-1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetLastHeight records that all heights up to h have been indexed.
+func (s *Store) SetLastHeight(h int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := setState(tx, stateKeyLastHeight, h); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PutBlock stores the decoded events of a block, and records that all
+// heights up to the block's height have been indexed.
+func (s *Store) PutBlock(height int64, t time.Time, evts []LiquidityEvent) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO blocks (height, time) VALUES (?, ?)`, height, t.UnixNano()); err != nil {
+		return fmt.Errorf("insert block: %w", err)
+	}
+	for i, evt := range evts {
+		if err := putEvent(tx, i, evt); err != nil {
+			return fmt.Errorf("insert %s event: %w", evt.GetType(), err)
+		}
+	}
+	if err := setState(tx, stateKeyLastHeight, height); err != nil {
+		return fmt.Errorf("set last height: %w", err)
+	}
+	return tx.Commit()
+}
+
+func putEvent(tx *sql.Tx, idx int, evt LiquidityEvent) error {
+	bz, err := json.Marshal(evt.GetAttributes())
+	if err != nil {
+		return fmt.Errorf("marshal attributes: %w", err)
+	}
+	res, err := tx.Exec(
+		`INSERT INTO events (height, idx, type, pool_id, attributes) VALUES (?, ?, ?, ?, ?)`,
+		evt.GetHeight(), idx, evt.GetType(), evt.GetPoolID(), string(bz))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	switch evt := evt.(type) {
+	case *SwapTransactedEvent:
+		_, err = tx.Exec(
+			`INSERT INTO swaps (event_id, height, pool_id, success, requester,
+				offer_denom, offer_amount, offer_fee_amount, demand_denom, demand_amount, demand_fee_amount)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, evt.Height, evt.PoolID, evt.Success, evt.SwapRequesterAddress,
+			evt.ExchangedOfferCoin.Denom, evt.ExchangedOfferCoin.Amount.String(), intString(evt.ExchangedOfferCoinFee),
+			evt.Attributes[liquiditytypes.AttributeValueDemandCoinDenom], intString(evt.ExchangedDemandCoin), intString(evt.ExchangedDemandCoinFee))
+	case *DepositToPoolEvent:
+		_, err = tx.Exec(
+			`INSERT INTO deposits (event_id, height, pool_id, success, depositor,
+				accepted_coins, refunded_coins, pool_coin_denom, pool_coin_amount)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, evt.Height, evt.PoolID, evt.Success, evt.DepositorAddress,
+			evt.AcceptedCoins.String(), evt.RefundedCoins.String(), evt.PoolCoin.Denom, intString(evt.PoolCoin))
+	case *WithdrawFromPoolEvent:
+		_, err = tx.Exec(
+			`INSERT INTO withdrawals (event_id, height, pool_id, success, withdrawer,
+				pool_coin_denom, pool_coin_amount, withdraw_coins, withdraw_fee_coins)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, evt.Height, evt.PoolID, evt.Success, evt.WithdrawerAddress,
+			evt.PoolCoin.Denom, intString(evt.PoolCoin), evt.WithdrawCoins.String(), evt.WithdrawFeeCoins.String())
+	}
+	return err
+}
+
+// intString returns the amount of coin as a string, treating an unset
+// amount as zero.
+func intString(coin sdk.Coin) string {
+	if coin.Amount.IsNil() {
+		return "0"
+	}
+	return coin.Amount.String()
+}
+
+// IterateEvents decodes the stored events of eventTypes emitted in
+// [beginHeight, endHeight] in the order they were emitted, and calls fn for
+// each of them.
+func (s *Store) IterateEvents(eventTypes []string, beginHeight, endHeight int64, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	args := []interface{}{beginHeight, endHeight}
+	for _, eventType := range eventTypes {
+		args = append(args, eventType)
+	}
+	rows, err := s.db.Query(
+		`SELECT e.height, b.time, e.type, e.attributes FROM events e JOIN blocks b ON b.height = e.height
+		WHERE e.height >= ? AND e.height <= ? AND e.type IN (`+strings.TrimSuffix(strings.Repeat("?,", len(eventTypes)), ",")+`)
+		ORDER BY e.height, e.idx`,
+		args...)
+	if err != nil {
+		return err
+	}
+	return iterateEventRows(rows, fn)
+}
+
+// IterateAddressEvents is like IterateEvents, but only for the events
+// involving addr as a swap requester, depositor or withdrawer.
+func (s *Store) IterateAddressEvents(addr string, beginHeight, endHeight int64, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	rows, err := s.db.Query(
+		`SELECT e.height, b.time, e.type, e.attributes FROM events e JOIN blocks b ON b.height = e.height
+		WHERE e.height >= ? AND e.height <= ? AND e.id IN (
+			SELECT event_id FROM swaps WHERE requester = ?
+			UNION ALL SELECT event_id FROM deposits WHERE depositor = ?
+			UNION ALL SELECT event_id FROM withdrawals WHERE withdrawer = ?)
+		ORDER BY e.height, e.idx`,
+		beginHeight, endHeight, addr, addr, addr)
+	if err != nil {
+		return err
+	}
+	return iterateEventRows(rows, fn)
+}
+
+func iterateEventRows(rows *sql.Rows, fn func(evt LiquidityEvent, blockTime time.Time) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		var height, blockTime int64
+		var eventType, attrsJSON string
+		if err := rows.Scan(&height, &blockTime, &eventType, &attrsJSON); err != nil {
+			return err
+		}
+		var attrs map[string]string
+		if err := json.Unmarshal([]byte(attrsJSON), &attrs); err != nil {
+			return fmt.Errorf("unmarshal attributes: %w", err)
+		}
+		event := abcitypes.Event{Type: eventType}
+		for k, v := range attrs {
+			event.Attributes = append(event.Attributes, abcitypes.EventAttribute{Key: []byte(k), Value: []byte(v)})
+		}
+		evt, ok, err := DecodeEvent(height, event)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := fn(evt, time.Unix(0, blockTime).UTC()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+type PoolSummary struct {
+	ID           uint64          `json:"id"`
+	ReserveCoins [2]sdk.Coin     `json:"reserve_coins"`
+	Swaps        [2]SwapSummary  `json:"swaps"`
+	Deposits     DepositSummary  `json:"deposits"`
+	Withdrawals  WithdrawSummary `json:"withdrawals"`
+	Value        *PoolValue      `json:"usd,omitempty"`
+}
+
+type SwapSummary struct {
+	OfferCoin     sdk.Coin `json:"offer_coin"`
+	OfferCoinFee  sdk.Coin `json:"offer_coin_fee"`
+	DemandCoin    sdk.Coin `json:"demand_coin"`
+	DemandCoinFee sdk.Coin `json:"demand_coin_fee"`
+}
+
+type DepositSummary struct {
+	Count          int         `json:"count"`
+	Coins          [2]sdk.Coin `json:"coins"`
+	PoolCoinMinted sdk.Coin    `json:"pool_coin_minted"`
+}
+
+type WithdrawSummary struct {
+	Count          int         `json:"count"`
+	Coins          [2]sdk.Coin `json:"coins"`
+	FeeCoins       [2]sdk.Coin `json:"fee_coins"`
+	PoolCoinBurned sdk.Coin    `json:"pool_coin_burned"`
+}
+
+// NewPoolSummary returns an empty summary for pool, with all amounts set to
+// zero coins of the pool's denoms.
+func NewPoolSummary(pool liquiditytypes.Pool) *PoolSummary {
+	ps := &PoolSummary{ID: pool.Id, Swaps: NewSwapSummaries(pool)}
+	for i, denom := range pool.ReserveCoinDenoms {
+		ps.ReserveCoins[i] = sdk.NewCoin(denom, sdk.ZeroInt())
+		ps.Deposits.Coins[i] = sdk.NewCoin(denom, sdk.ZeroInt())
+		ps.Withdrawals.Coins[i] = sdk.NewCoin(denom, sdk.ZeroInt())
+		ps.Withdrawals.FeeCoins[i] = sdk.NewCoin(denom, sdk.ZeroInt())
+	}
+	ps.Deposits.PoolCoinMinted = sdk.NewCoin(pool.PoolCoinDenom, sdk.ZeroInt())
+	ps.Withdrawals.PoolCoinBurned = sdk.NewCoin(pool.PoolCoinDenom, sdk.ZeroInt())
+	return ps
+}
+
+// Add adds a successful swap, deposit or withdrawal event to the summary.
+// Other events and failed requests are ignored.
+func (ps *PoolSummary) Add(evt LiquidityEvent) {
+	switch evt := evt.(type) {
+	case *SwapTransactedEvent:
+		if evt.Success {
+			ps.AddSwap(*evt)
+		}
+	case *DepositToPoolEvent:
+		if evt.Success {
+			ps.AddDeposit(*evt)
+		}
+	case *WithdrawFromPoolEvent:
+		if evt.Success {
+			ps.AddWithdrawal(*evt)
+		}
+	}
+}
+
+// AddSwap adds a successful swap to the summary.
+func (ps *PoolSummary) AddSwap(evt SwapTransactedEvent) {
+	addSwap(&ps.Swaps, evt)
+}
+
+// NewSwapSummaries returns empty swap summaries for both directions of pool.
+func NewSwapSummaries(pool liquiditytypes.Pool) [2]SwapSummary {
+	var swaps [2]SwapSummary
+	for i, denom := range pool.ReserveCoinDenoms {
+		swaps[i].OfferCoin = sdk.NewCoin(denom, sdk.ZeroInt())
+		swaps[i].OfferCoinFee = sdk.NewCoin(denom, sdk.ZeroInt())
+		swaps[i].DemandCoin = sdk.NewCoin(pool.ReserveCoinDenoms[1-i], sdk.ZeroInt())
+		swaps[i].DemandCoinFee = sdk.NewCoin(pool.ReserveCoinDenoms[1-i], sdk.ZeroInt())
+	}
+	return swaps
+}
+
+// addSwap adds a successful swap to the summary of its direction.
+func addSwap(swaps *[2]SwapSummary, evt SwapTransactedEvent) {
+	var i int
+	if evt.ExchangedOfferCoin.Denom == swaps[0].OfferCoin.Denom {
+		i = 0
+	} else {
+		i = 1
+	}
+	swaps[i].OfferCoin = swaps[i].OfferCoin.Add(evt.ExchangedOfferCoin)
+	swaps[i].OfferCoinFee = swaps[i].OfferCoinFee.Add(evt.ExchangedOfferCoinFee)
+	swaps[i].DemandCoin = swaps[i].DemandCoin.Add(evt.ExchangedDemandCoin)
+	swaps[i].DemandCoinFee = swaps[i].DemandCoinFee.Add(evt.ExchangedDemandCoinFee)
+}
+
+// AddDeposit adds a successful deposit to the summary.
+func (ps *PoolSummary) AddDeposit(evt DepositToPoolEvent) {
+	ps.Deposits.Count++
+	for i, coin := range ps.Deposits.Coins {
+		ps.Deposits.Coins[i] = sdk.NewCoin(coin.Denom, coin.Amount.Add(evt.AcceptedCoins.AmountOf(coin.Denom)))
+	}
+	ps.Deposits.PoolCoinMinted = ps.Deposits.PoolCoinMinted.Add(evt.PoolCoin)
+}
+
+// AddWithdrawal adds a successful withdrawal to the summary.
+func (ps *PoolSummary) AddWithdrawal(evt WithdrawFromPoolEvent) {
+	ps.Withdrawals.Count++
+	for i, coin := range ps.Withdrawals.Coins {
+		ps.Withdrawals.Coins[i] = sdk.NewCoin(coin.Denom, coin.Amount.Add(evt.WithdrawCoins.AmountOf(coin.Denom)))
+	}
+	for i, coin := range ps.Withdrawals.FeeCoins {
+		ps.Withdrawals.FeeCoins[i] = sdk.NewCoin(coin.Denom, coin.Amount.Add(evt.WithdrawFeeCoins.AmountOf(coin.Denom)))
+	}
+	ps.Withdrawals.PoolCoinBurned = ps.Withdrawals.PoolCoinBurned.Add(evt.PoolCoin)
+}
+
+// SummarizeEvents adds the events of src in [beginHeight, endHeight] to the
+// summaries of their pools, and returns the set of swap requesters.
+func SummarizeEvents(ctx context.Context, src EventSource, summaries []*PoolSummary, beginHeight, endHeight int64) (map[string]struct{}, error) {
+	byID := make(map[uint64]*PoolSummary)
+	for _, ps := range summaries {
+		byID[ps.ID] = ps
+	}
+	swapRequesters := make(map[string]struct{})
+	if err := src.IterateEvents(ctx, EndBlockEventTypes, beginHeight, endHeight, false, func(evt LiquidityEvent, _ time.Time) error {
+		// Failed requests may refer to pools that don't exist.
+		if failedEvent(evt) {
+			return nil
+		}
+		ps, ok := byID[evt.GetPoolID()]
+		if !ok {
+			return fmt.Errorf("pool id not found: %d", evt.GetPoolID())
+		}
+		ps.Add(evt)
+		if ste, ok := evt.(*SwapTransactedEvent); ok && ste.Success {
+			swapRequesters[ste.SwapRequesterAddress] = struct{}{}
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("iterate events: %w", err)
+	}
+	return swapRequesters, nil
+}
+
+// failedEvent reports whether evt is the result of a failed swap, deposit or
+// withdrawal.
+func failedEvent(evt LiquidityEvent) bool {
+	switch evt := evt.(type) {
+	case *SwapTransactedEvent:
+		return !evt.Success
+	case *DepositToPoolEvent:
+		return !evt.Success
+	case *WithdrawFromPoolEvent:
+		return !evt.Success
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	liquiditytypes "github.com/gravity-devs/liquidity/x/liquidity/types"
+)
+
+// TimeSeriesPoint summarizes the swaps of a pool within a time bucket.
+type TimeSeriesPoint struct {
+	Time       time.Time      `json:"time"`
+	PoolID     uint64         `json:"pool_id"`
+	Swaps      [2]SwapSummary `json:"swaps"`
+	NumSwaps   int            `json:"num_swaps"`
+	NumTraders int            `json:"num_traders"`
+
+	traders map[string]struct{}
+}
+
+type timeSeriesKey struct {
+	time   time.Time
+	poolID uint64
+}
+
+// TimeSeries groups successful swaps by pool and by block time buckets.
+// Buckets are aligned to multiples of the bucket size since the zero time,
+// so daily buckets start at midnight UTC.
+type TimeSeries struct {
+	bucket time.Duration
+	pools  map[uint64]liquiditytypes.Pool
+	points map[timeSeriesKey]*TimeSeriesPoint
+}
+
+func NewTimeSeries(pools []liquiditytypes.Pool, bucket time.Duration) *TimeSeries {
+	ts := &TimeSeries{
+		bucket: bucket,
+		pools:  make(map[uint64]liquiditytypes.Pool),
+		points: make(map[timeSeriesKey]*TimeSeriesPoint),
+	}
+	for _, pool := range pools {
+		ts.pools[pool.Id] = pool
+	}
+	return ts
+}
+
+// Add adds evt, emitted in a block at blockTime, to its bucket. Events other
+// than successful swaps are ignored.
+func (ts *TimeSeries) Add(evt LiquidityEvent, blockTime time.Time) error {
+	ste, ok := evt.(*SwapTransactedEvent)
+	if !ok || !ste.Success {
+		return nil
+	}
+	key := timeSeriesKey{blockTime.UTC().Truncate(ts.bucket), ste.PoolID}
+	p, ok := ts.points[key]
+	if !ok {
+		pool, ok := ts.pools[ste.PoolID]
+		if !ok {
+			return fmt.Errorf("pool id not found: %d", ste.PoolID)
+		}
+		p = &TimeSeriesPoint{
+			Time:    key.time,
+			PoolID:  key.poolID,
+			Swaps:   NewSwapSummaries(pool),
+			traders: make(map[string]struct{}),
+		}
+		ts.points[key] = p
+	}
+	addSwap(&p.Swaps, *ste)
+	p.NumSwaps++
+	p.traders[ste.SwapRequesterAddress] = struct{}{}
+	p.NumTraders = len(p.traders)
+	return nil
+}
+
+// Points returns the non-empty points ordered by time, then by pool id.
+func (ts *TimeSeries) Points() []*TimeSeriesPoint {
+	points := make([]*TimeSeriesPoint, 0, len(ts.points))
+	for _, p := range ts.points {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if !points[i].Time.Equal(points[j].Time) {
+			return points[i].Time.Before(points[j].Time)
+		}
+		return points[i].PoolID < points[j].PoolID
+	})
+	return points
+}
+
+type TimeSeriesReport struct {
+	SummaryMetadata
+	Bucket string             `json:"bucket"`
+	Points []*TimeSeriesPoint `json:"points"`
+}
+
+// WriteTimeSeriesReport writes report to w in the given format.
+func WriteTimeSeriesReport(w io.Writer, format string, report TimeSeriesReport) error {
+	switch format {
+	case FormatCSV:
+		return writeTimeSeriesCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, p := range report.Points {
+			if err := enc.Encode(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeTimeSeriesCSV(w io.Writer, report TimeSeriesReport) error {
+	records := [][]string{{
+		"time", "id", "x_denom", "y_denom", "swaps", "traders",
+		"offer_x", "offer_x_fee", "demand_y", "demand_y_fee",
+		"offer_y", "offer_y_fee", "demand_x", "demand_x_fee",
+	}}
+	for _, p := range report.Points {
+		records = append(records, []string{
+			p.Time.Format(time.RFC3339),
+			strconv.FormatUint(p.PoolID, 10),
+			p.Swaps[0].OfferCoin.Denom,
+			p.Swaps[1].OfferCoin.Denom,
+			strconv.Itoa(p.NumSwaps),
+			strconv.Itoa(p.NumTraders),
+			p.Swaps[0].OfferCoin.Amount.String(),
+			p.Swaps[0].OfferCoinFee.Amount.String(),
+			p.Swaps[0].DemandCoin.Amount.String(),
+			p.Swaps[0].DemandCoinFee.Amount.String(),
+			p.Swaps[1].OfferCoin.Amount.String(),
+			p.Swaps[1].OfferCoinFee.Amount.String(),
+			p.Swaps[1].DemandCoin.Amount.String(),
+			p.Swaps[1].DemandCoinFee.Amount.String(),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// TraderSummary is the swap activity of an address. Volume is the sum of
+// the coins offered, and Fees the sum of the offer and demand coin fees.
+type TraderSummary struct {
+	Address     string       `json:"address"`
+	NumSwaps    int          `json:"num_swaps"`
+	Volume      sdk.Coins    `json:"volume"`
+	Fees        sdk.Coins    `json:"fees"`
+	PoolIDs     []uint64     `json:"pool_ids"`
+	FirstHeight int64        `json:"first_height"`
+	LastHeight  int64        `json:"last_height"`
+	Value       *TraderValue `json:"usd,omitempty"`
+
+	pools map[uint64]struct{}
+}
+
+// Traders aggregates successful swaps by requester address.
+type Traders struct {
+	traders map[string]*TraderSummary
+}
+
+func NewTraders() *Traders {
+	return &Traders{traders: make(map[string]*TraderSummary)}
+}
+
+// Add adds evt to the summary of its requester. Events must be added in
+// the order they were emitted. Events other than successful swaps are
+// ignored.
+func (t *Traders) Add(evt LiquidityEvent, _ time.Time) error {
+	ste, ok := evt.(*SwapTransactedEvent)
+	if !ok || !ste.Success {
+		return nil
+	}
+	ts, ok := t.traders[ste.SwapRequesterAddress]
+	if !ok {
+		ts = &TraderSummary{
+			Address:     ste.SwapRequesterAddress,
+			Volume:      sdk.NewCoins(),
+			Fees:        sdk.NewCoins(),
+			FirstHeight: ste.Height,
+			pools:       make(map[uint64]struct{}),
+		}
+		t.traders[ste.SwapRequesterAddress] = ts
+	}
+	ts.NumSwaps++
+	ts.Volume = ts.Volume.Add(ste.ExchangedOfferCoin)
+	ts.Fees = ts.Fees.Add(sdk.NewCoins(ste.ExchangedOfferCoinFee, ste.ExchangedDemandCoinFee)...)
+	if _, ok := ts.pools[ste.PoolID]; !ok {
+		ts.pools[ste.PoolID] = struct{}{}
+		ts.PoolIDs = append(ts.PoolIDs, ste.PoolID)
+		sort.Slice(ts.PoolIDs, func(i, j int) bool { return ts.PoolIDs[i] < ts.PoolIDs[j] })
+	}
+	ts.LastHeight = ste.Height
+	return nil
+}
+
+// Trader sort keys.
+const (
+	TraderSortSwaps  = "swaps"
+	TraderSortVolume = "volume"
+	TraderSortFees   = "fees"
+	TraderSortPools  = "pools"
+	TraderSortFirst  = "first"
+	TraderSortLast   = "last"
+)
+
+// Traders returns the trader summaries in no particular order.
+func (t *Traders) Traders() []*TraderSummary {
+	traders := make([]*TraderSummary, 0, len(t.traders))
+	for _, ts := range t.traders {
+		traders = append(traders, ts)
+	}
+	return traders
+}
+
+// SortTraders sorts traders in descending order of sortBy, except for
+// TraderSortFirst which sorts in ascending order of first activity. Ties are
+// broken by address. Sorting by volume or fees requires the summaries to have
+// USD values.
+func SortTraders(traders []*TraderSummary, sortBy string) error {
+	var less func(a, b *TraderSummary) bool
+	switch sortBy {
+	case TraderSortSwaps:
+		less = func(a, b *TraderSummary) bool { return a.NumSwaps > b.NumSwaps }
+	case TraderSortVolume:
+		less = func(a, b *TraderSummary) bool { return traderUSD(a).Volume > traderUSD(b).Volume }
+	case TraderSortFees:
+		less = func(a, b *TraderSummary) bool { return traderUSD(a).Fees > traderUSD(b).Fees }
+	case TraderSortPools:
+		less = func(a, b *TraderSummary) bool { return len(a.PoolIDs) > len(b.PoolIDs) }
+	case TraderSortFirst:
+		less = func(a, b *TraderSummary) bool { return a.FirstHeight < b.FirstHeight }
+	case TraderSortLast:
+		less = func(a, b *TraderSummary) bool { return a.LastHeight > b.LastHeight }
+	default:
+		return fmt.Errorf("unknown sort key: %s", sortBy)
+	}
+	sort.Slice(traders, func(i, j int) bool {
+		if less(traders[i], traders[j]) {
+			return true
+		}
+		if less(traders[j], traders[i]) {
+			return false
+		}
+		return traders[i].Address < traders[j].Address
+	})
+	return nil
+}
+
+func traderUSD(ts *TraderSummary) TraderValue {
+	if ts.Value == nil {
+		return TraderValue{}
+	}
+	return *ts.Value
+}
+
+type TraderReport struct {
+	SummaryMetadata
+	Traders []*TraderSummary `json:"traders"`
+}
+
+// WriteTraderReport writes report to w in the given format.
+func WriteTraderReport(w io.Writer, format string, report TraderReport) error {
+	switch format {
+	case FormatCSV:
+		return writeTraderCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, ts := range report.Traders {
+			if err := enc.Encode(ts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTable:
+		return writeTraderTable(w, report)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeTraderCSV(w io.Writer, report TraderReport) error {
+	records := [][]string{{
+		"address", "swaps", "volume", "fees", "pools", "first_height", "last_height",
+		"volume_usd", "fee_usd",
+	}}
+	for _, ts := range report.Traders {
+		usd := make([]string, 2)
+		if ts.Value != nil {
+			usd[0] = formatUSD(ts.Value.Volume)
+			usd[1] = formatUSD(ts.Value.Fees)
+		}
+		records = append(records, []string{
+			ts.Address,
+			strconv.Itoa(ts.NumSwaps),
+			ts.Volume.String(),
+			ts.Fees.String(),
+			formatPoolIDs(ts.PoolIDs),
+			strconv.FormatInt(ts.FirstHeight, 10),
+			strconv.FormatInt(ts.LastHeight, 10),
+			usd[0], usd[1],
+		})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeTraderTable(w io.Writer, report TraderReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "#\tADDRESS\tSWAPS\tPOOLS\tFIRST\tLAST\tVOLUME USD\tFEE USD\t")
+	for i, ts := range report.Traders {
+		var volumeUSD, feeUSD string
+		if ts.Value != nil {
+			volumeUSD = formatUSD(ts.Value.Volume)
+			feeUSD = formatUSD(ts.Value.Fees)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%d\t%d\t%s\t%s\t\n",
+			i+1, ts.Address, ts.NumSwaps, formatPoolIDs(ts.PoolIDs),
+			ts.FirstHeight, ts.LastHeight, volumeUSD, feeUSD)
+	}
+	return tw.Flush()
+}
+
+func formatPoolIDs(ids []uint64) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+const maxReconnectDelay = time.Minute
+
+// Watcher follows new blocks over the websocket and passes their liquidity
+// end block events to a handler. New blocks only trigger fetching the
+// results of every block since the last one processed, so blocks missed
+// while disconnected are caught up on.
+type Watcher struct {
+	client      *Client
+	concurrency int
+	idleTimeout time.Duration
+
+	lastHeight int64
+}
+
+// NewWatcher returns a watcher starting at beginHeight, or after the latest
+// height if beginHeight is zero. The connection is considered lost if no
+// block arrives within idleTimeout.
+func NewWatcher(c *Client, beginHeight int64, concurrency int, idleTimeout time.Duration) *Watcher {
+	w := &Watcher{client: c, concurrency: concurrency, idleTimeout: idleTimeout}
+	if beginHeight > 0 {
+		w.lastHeight = beginHeight - 1
+	}
+	return w
+}
+
+// watchError is an error that reconnecting doesn't recover from, such as a
+// rejected websocket handshake or a failing handler.
+type watchError struct {
+	err error
+}
+
+func (e *watchError) Error() string {
+	return e.err.Error()
+}
+
+// Run calls fn for each event in the order they were emitted until ctx is
+// done or fn returns an error, reconnecting with increasing delays whenever
+// the connection is lost.
+func (w *Watcher) Run(ctx context.Context, fn func(evt LiquidityEvent) error) error {
+	if w.lastHeight == 0 {
+		h, err := w.client.LatestBlockHeight(ctx)
+		if err != nil {
+			return fmt.Errorf("get latest block height: %w", err)
+		}
+		w.lastHeight = h
+	}
+	delay := time.Second
+	for {
+		lastHeight := w.lastHeight
+		err := w.follow(ctx, fn)
+		if ctx.Err() != nil {
+			return nil
+		}
+		var werr *watchError
+		if errors.As(err, &werr) {
+			return werr.err
+		}
+		if w.lastHeight > lastHeight {
+			delay = time.Second
+		}
+		log.Printf("watch: %v; reconnecting in %s", err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (w *Watcher) follow(ctx context.Context, fn func(evt LiquidityEvent) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	heights, err := w.client.SubscribeNewBlockHeights(ctx)
+	if err != nil {
+		// A rejected handshake won't succeed on retry.
+		if errors.Is(err, websocket.ErrBadHandshake) {
+			return &watchError{err}
+		}
+		return err
+	}
+
+	// Catch up on blocks produced while not subscribed.
+	latest, err := w.client.LatestBlockHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("get latest block height: %w", err)
+	}
+	if err := w.catchUp(ctx, latest, fn); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(w.idleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return fmt.Errorf("no new block for %s", w.idleTimeout)
+		case h, ok := <-heights:
+			if !ok {
+				return fmt.Errorf("subscription closed")
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(w.idleTimeout)
+			if err := w.catchUp(ctx, h, fn); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// catchUp processes the blocks after the last one processed up to height.
+func (w *Watcher) catchUp(ctx context.Context, height int64, fn func(evt LiquidityEvent) error) error {
+	if height <= w.lastHeight {
+		return nil
+	}
+	heights := make([]int64, 0, height-w.lastHeight)
+	for h := w.lastHeight + 1; h <= height; h++ {
+		heights = append(heights, h)
+	}
+	return w.client.IterateEndBlockEvents(ctx, heights, w.concurrency, func(h int64, events []abcitypes.Event) error {
+		evts, err := DecodeEvents(h, events)
+		if err != nil {
+			return &watchError{fmt.Errorf("decode events: %w", err)}
+		}
+		for _, evt := range evts {
+			if err := fn(evt); err != nil {
+				return &watchError{err}
+			}
+		}
+		w.lastHeight = h
+		return nil
+	})
+}